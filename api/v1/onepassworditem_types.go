@@ -37,6 +37,49 @@ type OnePasswordItemSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	ItemPath string `json:"itemPath,omitempty"`
+
+	// Keystore, when set, assembles Java keystore and truststore bundles from PEM content
+	// of the item and adds them to the generated Kubernetes secret.
+	// +optional
+	Keystore *OnePasswordItemKeystore `json:"keystore,omitempty"`
+}
+
+// KeystoreFormat is the encoding of a generated Java keystore bundle.
+// +kubebuilder:validation:Enum=PKCS12;JKS
+type KeystoreFormat string
+
+const (
+	// KeystoreFormatPKCS12 produces keystore.p12 and truststore.p12.
+	KeystoreFormatPKCS12 KeystoreFormat = "PKCS12"
+	// KeystoreFormatJKS produces keystore.jks and truststore.jks.
+	KeystoreFormatJKS KeystoreFormat = "JKS"
+)
+
+// OnePasswordItemKeystore defines how Java keystore bundles are built from PEM fields or files.
+type OnePasswordItemKeystore struct {
+	// Formats of the bundles to generate.
+	// +kubebuilder:validation:MinItems=1
+	Formats []KeystoreFormat `json:"formats"`
+	// Additional item paths searched for PEM content after the main item,
+	// e.g. an item holding the CA certificates for the truststore.
+	// +optional
+	ItemPaths []string `json:"itemPaths,omitempty"`
+	// Label of the field or name of the file holding the PEM certificate chain. Defaults to "tls.crt".
+	// +optional
+	CertificateField string `json:"certificateField,omitempty"`
+	// Label of the field or name of the file holding the PEM private key. Defaults to "tls.key".
+	// +optional
+	PrivateKeyField string `json:"privateKeyField,omitempty"`
+	// Label of the field or name of the file holding PEM CA certificates for the truststore. Defaults to "ca.crt".
+	// +optional
+	CACertificatesField string `json:"caCertificatesField,omitempty"`
+	// Label of the field holding the keystore password. If not set, a password is
+	// generated and stored in the secret under the "keystore-password" key.
+	// +optional
+	PasswordField string `json:"passwordField,omitempty"`
+	// Alias of the private key entry. Defaults to "default".
+	// +optional
+	Alias string `json:"alias,omitempty"`
 }
 
 type OnePasswordItemConditionType string
@@ -46,6 +89,22 @@ const (
 	OnePasswordItemReady OnePasswordItemConditionType = "Ready"
 )
 
+// Reasons of the Ready condition.
+const (
+	// ReasonSynced means the secret is in sync with the 1Password item.
+	ReasonSynced = "Synced"
+	// ReasonItemNotFound means the item path does not match a vault or item in 1Password.
+	ReasonItemNotFound = "ItemNotFound"
+	// ReasonUnauthorized means the operator credentials do not give access to the item.
+	ReasonUnauthorized = "Unauthorized"
+	// ReasonRateLimited means 1Password rate limited the operator, the sync is retried later.
+	ReasonRateLimited = "RateLimited"
+	// ReasonUnavailable means 1Password could not be reached, the sync is retried later.
+	ReasonUnavailable = "Unavailable"
+	// ReasonSyncFailed means the secret could not be synced for any other reason.
+	ReasonSyncFailed = "SyncFailed"
+)
+
 type OnePasswordItemCondition struct {
 	// Type of job condition, Completed.
 	Type OnePasswordItemConditionType `json:"type"`
@@ -54,6 +113,9 @@ type OnePasswordItemCondition struct {
 	// Last time the condition transit from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Machine-readable reason for the condition's last update.
+	// +optional
+	Reason string `json:"reason,omitempty"`
 	// Human-readable message indicating details about last transition.
 	// +optional
 	Message string `json:"message,omitempty"`
@@ -37,7 +37,7 @@ func (in *OnePasswordItem) DeepCopyInto(out *OnePasswordItem) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -75,6 +75,31 @@ func (in *OnePasswordItemCondition) DeepCopy() *OnePasswordItemCondition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OnePasswordItemKeystore) DeepCopyInto(out *OnePasswordItemKeystore) {
+	*out = *in
+	if in.Formats != nil {
+		in, out := &in.Formats, &out.Formats
+		*out = make([]KeystoreFormat, len(*in))
+		copy(*out, *in)
+	}
+	if in.ItemPaths != nil {
+		in, out := &in.ItemPaths, &out.ItemPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnePasswordItemKeystore.
+func (in *OnePasswordItemKeystore) DeepCopy() *OnePasswordItemKeystore {
+	if in == nil {
+		return nil
+	}
+	out := new(OnePasswordItemKeystore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OnePasswordItemList) DeepCopyInto(out *OnePasswordItemList) {
 	*out = *in
@@ -110,6 +135,11 @@ func (in *OnePasswordItemList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OnePasswordItemSpec) DeepCopyInto(out *OnePasswordItemSpec) {
 	*out = *in
+	if in.Keystore != nil {
+		in, out := &in.Keystore, &out.Keystore
+		*out = new(OnePasswordItemKeystore)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OnePasswordItemSpec.
@@ -32,7 +32,6 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -42,13 +41,16 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
@@ -57,8 +59,15 @@ import (
 
 	onepasswordcomv1 "github.com/1Password/onepassword-operator/api/v1"
 	"github.com/1Password/onepassword-operator/internal/controller"
+	webhookv1 "github.com/1Password/onepassword-operator/internal/webhook/v1"
+	"github.com/1Password/onepassword-operator/pkg/injector"
 	op "github.com/1Password/onepassword-operator/pkg/onepassword"
 	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/connect"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/sdk"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
 	"github.com/1Password/onepassword-operator/pkg/utils"
 	"github.com/1Password/onepassword-operator/version"
 	// +kubebuilder:scaffold:imports
@@ -70,12 +79,30 @@ var (
 )
 
 const (
-	envPollingIntervalVariable  = "POLLING_INTERVAL"
-	manageConnect               = "MANAGE_CONNECT"
-	restartWorkloadsEnvVariable = "AUTO_RESTART"
-	defaultPollingInterval      = 600
-
-	annotationRegExpString = "^operator\\.1password\\.io\\/[a-zA-Z\\.]+"
+	envPodNameVariable               = "POD_NAME"
+	envPollingIntervalVariable       = "POLLING_INTERVAL"
+	envPollingConcurrencyVariable    = "POLLING_CONCURRENCY"
+	envPollingItemTimeoutVariable    = "POLLING_ITEM_TIMEOUT"
+	envPollingBatchByVaultVariable   = "POLLING_BATCH_BY_VAULT"
+	envTitleCacheTTLVariable         = "TITLE_CACHE_TTL"
+	manageConnect                    = "MANAGE_CONNECT"
+	restartWorkloadsEnvVariable      = "AUTO_RESTART"
+	restartWorkloadTypesEnvVariable  = "AUTO_RESTART_WORKLOAD_TYPES"
+	restartModeEnvVariable           = "AUTO_RESTART_MODE"
+	restartSkipLiveMountsEnvVariable = "AUTO_RESTART_SKIP_LIVE_MOUNTS"
+	restartAnnotationEnvVariable     = "AUTO_RESTART_CUSTOM_ANNOTATION"
+	restartMaxConcurrentEnvVariable  = "AUTO_RESTART_MAX_CONCURRENT"
+	restartIntervalEnvVariable       = "AUTO_RESTART_INTERVAL"
+	restartRolloutTimeoutEnvVariable = "AUTO_RESTART_ROLLOUT_TIMEOUT"
+	restartDebounceEnvVariable       = "AUTO_RESTART_DEBOUNCE"
+	restartMaxPerHourEnvVariable     = "AUTO_RESTART_MAX_PER_HOUR"
+	syncRetryPolicyEnvVariable       = "SYNC_RETRY_POLICY"
+	connectRetryPolicyEnvVariable    = "CONNECT_RETRY_POLICY"
+	sdkRetryPolicyEnvVariable        = "SDK_RETRY_POLICY"
+	connectRateLimitEnvVariable      = "CONNECT_RATE_LIMIT"
+	sdkRateLimitEnvVariable          = "SDK_RATE_LIMIT"
+	tracingSamplingRatioEnvVariable  = "TRACING_SAMPLING_RATIO"
+	defaultPollingInterval           = 600
 )
 
 func printVersion() {
@@ -93,6 +120,16 @@ func init() {
 }
 
 func main() {
+	// The Pod webhook copies this binary into pods, where it resolves op:// references before running
+	// the container's command.
+	if injector.IsCommand(os.Args) {
+		if err := injector.Main(context.Background(), os.Args); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
@@ -102,6 +139,10 @@ func main() {
 	var enableHTTP2 bool
 	var enableAnnotations bool
 	var allowEmptyValues bool
+	var enableWebhooks bool
+	var injectorImage string
+	var injectorURL string
+	var onlineValidation bool
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080",
 		"The address the metrics endpoint binds to. "+
@@ -113,6 +154,18 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.BoolVar(&secureMetrics, "metrics-secure", true,
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
+	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
+	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
+	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the operator serves the admission webhooks. Requires --webhook-cert-path and --injector-url.")
+	flag.BoolVar(&onlineValidation, "webhook-online-validation", false,
+		"If set, the validating webhooks check that the vault and item of an item path exist in 1Password.")
+	flag.StringVar(&injectorImage, "injector-image", webhookv1.DefaultInjectorImage,
+		"The operator image the injector is copied from into pods annotated for env var injection.")
+	flag.StringVar(&injectorURL, "injector-url", "",
+		"The URL injected pods resolve op:// references with, served by the webhook Service on the "+
+			"/resolve path, e.g. https://webhook-service.<namespace>.svc/resolve. Required with --enable-webhooks.")
 	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt",
@@ -288,45 +341,111 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Setup tracing
+	if tracing.Enabled() {
+		shutdownTracing, err := tracing.Setup(ctx, tracing.Config{
+			SamplingRatio:  getTracingSamplingRatio(),
+			ServiceVersion: version.OperatorVersion,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to set up tracing")
+			os.Exit(1)
+		}
+		defer func() {
+			// The signal handler context is done by now, give the remaining spans a moment to be exported
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(shutdownCtx); err != nil {
+				setupLog.Error(err, "failed to export remaining traces")
+			}
+		}()
+	}
+
 	// Setup One Password Client
 	opClient, err := opclient.NewFromEnvironment(ctx, opclient.Config{
-		Logger:  setupLog,
-		Version: version.OperatorVersion,
+		Logger:             setupLog,
+		Version:            version.OperatorVersion,
+		ConnectRetryPolicy: getRetryPolicy(connectRetryPolicyEnvVariable, connect.DefaultRetryPolicy()),
+		SDKRetryPolicy:     getRetryPolicy(sdkRetryPolicyEnvVariable, sdk.DefaultRetryPolicy()),
+		ConnectRateLimit:   getRateLimit(connectRateLimitEnvVariable, connect.DefaultRateLimit()),
+		SDKRateLimit:       getRateLimit(sdkRateLimitEnvVariable, sdk.DefaultRateLimit()),
+		TitleCacheTTL:      getTitleCacheTTL(),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to create 1Password client")
 		os.Exit(1)
 	}
 
+	syncRetryPolicy := getRetryPolicy(syncRetryPolicyEnvVariable, retry.DefaultPolicy())
+
 	if err = (&controller.OnePasswordItemReconciler{
 		Client:   mgr.GetClient(),
 		Scheme:   mgr.GetScheme(),
 		OpClient: opClient,
+		Recorder: mgr.GetEventRecorderFor("onepassword-operator-onepassworditem"),
 		Config: controller.ReconcilerConfig{
 			EnableAnnotations: enableAnnotations,
 			AllowEmptyValues:  allowEmptyValues,
+			RetryPolicy:       syncRetryPolicy,
 		},
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "OnePasswordItem")
 		os.Exit(1)
 	}
 
-	r, _ := regexp.Compile(annotationRegExpString)
-	if err = (&controller.DeploymentReconciler{
-		Client:             mgr.GetClient(),
-		Scheme:             mgr.GetScheme(),
-		OpClient:           opClient,
-		OpAnnotationRegExp: r,
-		Recorder:           mgr.GetEventRecorderFor("onepassword-operator-deployment"),
-		Config: controller.ReconcilerConfig{
-			// to allow for custom annotations to be used for deployments
-			// can be implemented in the future PR
-			// EnableAnnotations: enableAnnotations,
-			AllowEmptyValues: allowEmptyValues,
-		},
-	}).SetupWithManager(mgr); err != nil {
-		setupLog.Error(err, "unable to create controller", "controller", "Deployment")
-		os.Exit(1)
+	for _, workload := range op.NewWorkloads() {
+		gvk, err := apiutil.GVKForObject(workload, mgr.GetScheme())
+		if err != nil {
+			setupLog.Error(err, "unable to resolve workload kind")
+			os.Exit(1)
+		}
+		if err = (&controller.WorkloadReconciler{
+			Client:             mgr.GetClient(),
+			Scheme:             mgr.GetScheme(),
+			OpClient:           opClient,
+			OpAnnotationRegExp: op.AnnotationRegExp,
+			Recorder:           mgr.GetEventRecorderFor("onepassword-operator-" + strings.ToLower(gvk.Kind)),
+			Config: controller.ReconcilerConfig{
+				// to allow for custom annotations to be used for workloads
+				// can be implemented in the future PR
+				// EnableAnnotations: enableAnnotations,
+				AllowEmptyValues: allowEmptyValues,
+				RetryPolicy:      syncRetryPolicy,
+			},
+			Workload: workload,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", gvk.Kind)
+			os.Exit(1)
+		}
+	}
+
+	if enableWebhooks {
+		if injectorURL == "" {
+			setupLog.Error(errors.New("--injector-url must be set"), "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+		injectorConfig := webhookv1.PodInjectorConfig{Image: injectorImage, ResolveURL: injectorURL}
+		if webhookCertPath != "" {
+			injectorConfig.CAFile = filepath.Join(webhookCertPath, "ca.crt")
+		}
+		if err = webhookv1.SetupPodWebhookWithManager(mgr, injectorConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Pod")
+			os.Exit(1)
+		}
+		webhookv1.SetupReferenceResolverWithManager(mgr, opClient)
+
+		validatorConfig := webhookv1.ValidatorConfig{}
+		if onlineValidation {
+			validatorConfig.OpClient = opClient
+		}
+		if err = webhookv1.SetupOnePasswordItemWebhookWithManager(mgr, validatorConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "OnePasswordItem")
+			os.Exit(1)
+		}
+		if err = webhookv1.SetupWorkloadWebhooksWithManager(mgr, validatorConfig); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Workload")
+			os.Exit(1)
+		}
 	}
 	// +kubebuilder:scaffold:builder
 
@@ -354,27 +473,39 @@ func main() {
 	// Setup update secrets task
 	updatedSecretsPoller := op.NewSecretUpdateHandler(
 		mgr.GetClient(), mgr.GetAPIReader(), opClient,
+		mgr.GetEventRecorderFor("onepassword-operator-secret-update-handler"),
 		op.SecretUpdateHandlerConfig{
+			PollingInterval:                    getPollingIntervalForUpdatingSecrets(),
+			PollingConcurrency:                 getPollingConcurrency(),
+			ItemTimeout:                        getPollingItemTimeout(),
+			BatchByVault:                       shouldBatchPollingByVault(),
 			ShouldAutoRestartWorkloadsGlobally: shouldAutoRestartWorkloads(),
 			AllowEmptyValues:                   allowEmptyValues,
 			WatchedNamespaces:                  watchedNamespaces,
+			ExtraWorkloadTypes:                 getExtraWorkloadTypes(),
+			RestartMode:                        getRestartMode(),
+			SkipRestartsForLiveMounts:          shouldSkipRestartsForLiveMounts(),
+			CustomRestartAnnotation:            getCustomRestartAnnotation(),
+			MaxConcurrentRestarts:              getMaxConcurrentRestarts(),
+			RestartInterval:                    getRestartInterval(),
+			RolloutTimeout:                     getRestartRolloutTimeout(),
+			RestartDebounceWindow:              getRestartDebounceWindow(),
+			MaxRestartsPerHour:                 getMaxRestartsPerHour(),
+			RetryPolicy:                        syncRetryPolicy,
+			OperatorPod:                        getOperatorPod(deploymentNamespace),
 		})
-	done := make(chan bool)
-	ticker := time.NewTicker(getPollingIntervalForUpdatingSecrets())
-	go func(ctx context.Context) {
-		for {
-			select {
-			case <-done:
-				ticker.Stop()
-				return
-			case <-ticker.C:
-				err := updatedSecretsPoller.UpdateKubernetesSecretsTask(ctx)
-				if err != nil {
-					setupLog.Error(err, "error running update kubernetes secret task")
-				}
-			}
+	if err := mgr.Add(updatedSecretsPoller); err != nil {
+		setupLog.Error(err, "unable to add update kubernetes secrets task to manager")
+		os.Exit(1)
+	}
+
+	if webhookCertWatcher != nil {
+		setupLog.Info("Adding webhook certificate watcher to manager")
+		if err := mgr.Add(webhookCertWatcher); err != nil {
+			setupLog.Error(err, "Unable to add webhook certificate watcher to manager")
+			os.Exit(1)
 		}
-	}(ctx)
+	}
 
 	if metricsCertWatcher != nil {
 		setupLog.Info("Adding metrics certificate watcher to manager")
@@ -392,6 +523,10 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("secret-poller", updatedSecretsPoller.ReadyzCheck); err != nil {
+		setupLog.Error(err, "unable to set up ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctx); err != nil {
@@ -440,6 +575,167 @@ func shouldAutoRestartWorkloads() bool {
 	return false
 }
 
+func shouldBatchPollingByVault() bool {
+	value, found := os.LookupEnv(envPollingBatchByVaultVariable)
+	if found {
+		batchByVault, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", envPollingBatchByVaultVariable))
+			os.Exit(1)
+		}
+		return batchByVault
+	}
+	return false
+}
+
+func getExtraWorkloadTypes() []op.WorkloadType {
+	value, found := os.LookupEnv(restartWorkloadTypesEnvVariable)
+	if !found {
+		return nil
+	}
+	workloadTypes, err := op.ParseWorkloadTypes(value)
+	if err != nil {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", restartWorkloadTypesEnvVariable))
+		os.Exit(1)
+	}
+	return workloadTypes
+}
+
+func shouldSkipRestartsForLiveMounts() bool {
+	value, found := os.LookupEnv(restartSkipLiveMountsEnvVariable)
+	if found {
+		skipLiveMounts, err := strconv.ParseBool(strings.ToLower(value))
+		if err != nil {
+			setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", restartSkipLiveMountsEnvVariable))
+			os.Exit(1)
+		}
+		return skipLiveMounts
+	}
+	return false
+}
+
+func getRestartMode() op.RestartMode {
+	mode, err := op.ParseRestartMode(os.Getenv(restartModeEnvVariable))
+	if err != nil {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", restartModeEnvVariable))
+		os.Exit(1)
+	}
+	return mode
+}
+
+func getCustomRestartAnnotation() string {
+	annotation := os.Getenv(restartAnnotationEnvVariable)
+	if annotation == "" {
+		return ""
+	}
+	if errs := validation.IsQualifiedName(annotation); len(errs) > 0 {
+		setupLog.Error(errors.New(strings.Join(errs, "; ")),
+			fmt.Sprintf("Invalid value set for %s. Must be a valid annotation key.", restartAnnotationEnvVariable))
+		os.Exit(1)
+	}
+	return annotation
+}
+
+func getMaxConcurrentRestarts() int {
+	value, found := os.LookupEnv(restartMaxConcurrentEnvVariable)
+	if !found {
+		return 0
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a positive integer.", restartMaxConcurrentEnvVariable))
+		os.Exit(1)
+	}
+	return concurrency
+}
+
+func getRestartInterval() time.Duration {
+	value, found := os.LookupEnv(restartIntervalEnvVariable)
+	if !found {
+		return 0
+	}
+	timeInSeconds, err := strconv.Atoi(value)
+	if err != nil || timeInSeconds < 0 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a non-negative integer.", restartIntervalEnvVariable))
+		os.Exit(1)
+	}
+	return time.Duration(timeInSeconds) * time.Second
+}
+
+func getRestartRolloutTimeout() time.Duration {
+	value, found := os.LookupEnv(restartRolloutTimeoutEnvVariable)
+	if !found {
+		return 0
+	}
+	timeInSeconds, err := strconv.Atoi(value)
+	if err != nil || timeInSeconds < 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a positive integer.", restartRolloutTimeoutEnvVariable))
+		os.Exit(1)
+	}
+	return time.Duration(timeInSeconds) * time.Second
+}
+
+func getRestartDebounceWindow() time.Duration {
+	value, found := os.LookupEnv(restartDebounceEnvVariable)
+	if !found {
+		return 0
+	}
+	timeInSeconds, err := strconv.Atoi(value)
+	if err != nil || timeInSeconds < 0 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a non-negative integer.", restartDebounceEnvVariable))
+		os.Exit(1)
+	}
+	return time.Duration(timeInSeconds) * time.Second
+}
+
+func getMaxRestartsPerHour() int {
+	value, found := os.LookupEnv(restartMaxPerHourEnvVariable)
+	if !found {
+		return 0
+	}
+	restarts, err := strconv.Atoi(value)
+	if err != nil || restarts < 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a positive integer.", restartMaxPerHourEnvVariable))
+		os.Exit(1)
+	}
+	return restarts
+}
+
+// getOperatorPod returns the pod the operator runs in, as set in POD_NAME by the manager Deployment.
+func getOperatorPod(namespace string) *corev1.ObjectReference {
+	name, found := os.LookupEnv(envPodNameVariable)
+	if !found || name == "" {
+		return nil
+	}
+	return &corev1.ObjectReference{APIVersion: "v1", Kind: "Pod", Namespace: namespace, Name: name}
+}
+
+func getRetryPolicy(envVariable string, defaults retry.Policy) retry.Policy {
+	value, found := os.LookupEnv(envVariable)
+	if !found {
+		return defaults
+	}
+	policy, err := retry.ParsePolicy(value, defaults)
+	if err != nil {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", envVariable))
+		os.Exit(1)
+	}
+	return policy
+}
+
+func getRateLimit(envVariable string, defaults ratelimit.Limit) ratelimit.Limit {
+	value, found := os.LookupEnv(envVariable)
+	if !found {
+		return defaults
+	}
+	limit, err := ratelimit.ParseLimit(value, defaults)
+	if err != nil {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s", envVariable))
+		os.Exit(1)
+	}
+	return limit
+}
+
 func getPollingIntervalForUpdatingSecrets() time.Duration {
 	timeInSecondsString, found := os.LookupEnv(envPollingIntervalVariable)
 	if found {
@@ -453,3 +749,59 @@ func getPollingIntervalForUpdatingSecrets() time.Duration {
 	setupLog.Info(fmt.Sprintf("Using default polling interval of %v seconds", defaultPollingInterval))
 	return time.Duration(defaultPollingInterval) * time.Second
 }
+
+func getPollingConcurrency() int {
+	value, found := os.LookupEnv(envPollingConcurrencyVariable)
+	if !found {
+		return 0
+	}
+	concurrency, err := strconv.Atoi(value)
+	if err != nil || concurrency < 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a positive integer.", envPollingConcurrencyVariable))
+		os.Exit(1)
+	}
+	return concurrency
+}
+
+func getPollingItemTimeout() time.Duration {
+	value, found := os.LookupEnv(envPollingItemTimeoutVariable)
+	if !found {
+		return 0
+	}
+	timeInSeconds, err := strconv.Atoi(value)
+	if err != nil || timeInSeconds < 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a positive integer.", envPollingItemTimeoutVariable))
+		os.Exit(1)
+	}
+	return time.Duration(timeInSeconds) * time.Second
+}
+
+func getTitleCacheTTL() time.Duration {
+	value, found := os.LookupEnv(envTitleCacheTTLVariable)
+	if !found {
+		return 0
+	}
+	timeInSeconds, err := strconv.Atoi(value)
+	if err != nil || timeInSeconds < 0 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a non-negative integer.", envTitleCacheTTLVariable))
+		os.Exit(1)
+	}
+	if timeInSeconds == 0 {
+		// Disables the cache
+		return -1
+	}
+	return time.Duration(timeInSeconds) * time.Second
+}
+
+func getTracingSamplingRatio() float64 {
+	value, found := os.LookupEnv(tracingSamplingRatioEnvVariable)
+	if !found {
+		return 1
+	}
+	ratio, err := strconv.ParseFloat(value, 64)
+	if err != nil || ratio < 0 || ratio > 1 {
+		setupLog.Error(err, fmt.Sprintf("Invalid value set for %s. Must be a number between 0 and 1.", tracingSamplingRatioEnvVariable))
+		os.Exit(1)
+	}
+	return ratio
+}
@@ -1,6 +1,18 @@
 package controller
 
+import "github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+
 type ReconcilerConfig struct {
 	EnableAnnotations bool
 	AllowEmptyValues  bool
+	// RetryPolicy decides when to sync a secret again after a failure. Defaults to retry.DefaultPolicy.
+	RetryPolicy retry.Policy
+}
+
+// retryPolicy returns the configured retry policy, or the default one if none is set.
+func (c ReconcilerConfig) retryPolicy() retry.Policy {
+	if c.RetryPolicy == (retry.Policy{}) {
+		return retry.DefaultPolicy()
+	}
+	return c.RetryPolicy
 }
@@ -0,0 +1,65 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// recordItemError records an ItemNotFound event on owner when its item path does not resolve.
+func recordItemError(recorder record.EventRecorder, owner client.Object, err error) {
+	if errors.Is(err, opclient.ErrNotFound) {
+		recorder.Event(owner, corev1.EventTypeWarning, op.ReasonItemNotFound, err.Error())
+	}
+}
+
+// recordSecretSynced records Synced and FieldSkipped events on owner and on the secret returned by
+// CreateKubernetesSecretFromItem. Nothing is recorded when the secret was already up to date.
+func recordSecretSynced(recorder record.EventRecorder, owner client.Object, secret *corev1.Secret, item *model.Item,
+	allowEmptyValues bool) {
+	if secret == nil {
+		return
+	}
+
+	message := fmt.Sprintf("Secret %q synced from item version %d", secret.Name, item.Version)
+	skipped := kubeSecrets.SkippedItemFields(*item, allowEmptyValues, kubeSecrets.IsCategoryPresetEnabled(secret.Annotations))
+	for _, target := range []runtime.Object{owner, secret} {
+		recorder.Event(target, corev1.EventTypeNormal, op.ReasonSynced, message)
+		for _, reason := range skipped {
+			recorder.Event(target, corev1.EventTypeWarning, op.ReasonFieldSkipped, reason)
+		}
+	}
+}
@@ -27,24 +27,31 @@ package controller
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
 	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
 	"github.com/1Password/onepassword-operator/pkg/logs"
 	op "github.com/1Password/onepassword-operator/pkg/onepassword"
 	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
 	"github.com/1Password/onepassword-operator/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
 var logOnePasswordItem = logf.Log.WithName("controller_onepassworditem")
@@ -55,6 +62,7 @@ type OnePasswordItemReconciler struct {
 	client.Client
 	Scheme   *runtime.Scheme
 	OpClient opclient.Client
+	Recorder record.EventRecorder
 	Config   ReconcilerConfig
 }
 
@@ -81,6 +89,14 @@ type OnePasswordItemReconciler struct {
 // For more details, check Reconcile and its Result here:
 // - https://pkg.go.dev/sigs.k8s.io/controller-runtime/pkg/reconcile
 func (r *OnePasswordItemReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "OnePasswordItemReconciler.Reconcile",
+		attribute.String("k8s.namespace.name", req.Namespace), attribute.String("k8s.object.name", req.Name))
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *OnePasswordItemReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	reqLogger := logOnePasswordItem.WithValues("Request.Namespace", req.Namespace, "Request.Name", req.Name)
 	reqLogger.V(logs.DebugLevel).Info("Reconciling OnePasswordItem")
 
@@ -107,15 +123,16 @@ func (r *OnePasswordItemReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 		// Handles creation or updating secrets for deployment if needed
 		err = r.handleOnePasswordItem(ctx, onepassworditem, req)
 		if err != nil {
-			if strings.Contains(err.Error(), "rate limit") {
-				reqLogger.V(logs.InfoLevel).Info("1Password rate limit hit. Requeuing after 15 minutes.")
-				return ctrl.Result{RequeueAfter: 15 * time.Minute}, nil
-			}
+			op.ObserveSecretSyncFailed(req.Namespace)
 		}
 		if updateStatusErr := r.updateStatus(ctx, onepassworditem, err); updateStatusErr != nil {
 			return ctrl.Result{}, fmt.Errorf("cannot update status: %s", updateStatusErr)
 		}
-		return ctrl.Result{}, err
+		result, resultErr := resultForError(err)
+		if err != nil && resultErr == nil {
+			reqLogger.V(logs.InfoLevel).Info(fmt.Sprintf("1Password rate limit hit. Requeuing after %s.", result.RequeueAfter))
+		}
+		return result, resultErr
 	}
 	// If one password finalizer exists then we must cleanup associated secrets
 	if utils.ContainsString(onepassworditem.Finalizers, finalizer) {
@@ -134,13 +151,58 @@ func (r *OnePasswordItemReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 }
 
 // SetupWithManager sets up the controller with the Manager.
+// Generated secrets are watched so that edits and deletions made outside of the operator are reverted.
 func (r *OnePasswordItemReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&onepasswordv1.OnePasswordItem{}).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &onepasswordv1.OnePasswordItem{}),
+			builder.WithPredicates(secretDriftPredicate()),
+		).
 		Named("onepassworditem").
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.Config.retryPolicy())}).
 		Complete(r)
 }
 
+// secretDriftPredicate only lets through secret events the operator did not cause itself,
+// so that writing a secret does not trigger another fetch from 1Password.
+func secretDriftPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			secret, ok := e.ObjectNew.(*corev1.Secret)
+			return ok && kubeSecrets.IsDrifted(secret)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return true },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// recordSecretDrift emits an event when the generated secret was changed or deleted outside of the operator.
+// The secret is restored by the following call to CreateKubernetesSecretFromItem.
+func (r *OnePasswordItemReconciler) recordSecretDrift(ctx context.Context, resource *onepasswordv1.OnePasswordItem) error {
+	secretName := kubeSecrets.FormatSecretName(resource.GetName())
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: resource.Namespace}, secret)
+	if errors.IsNotFound(err) {
+		// Only a secret that was synced before can have been deleted.
+		if findCondition(resource.Status.Conditions, onepasswordv1.OnePasswordItemReady).Status == metav1.ConditionTrue {
+			r.Recorder.Event(resource, corev1.EventTypeWarning, op.ReasonSecretDeleted,
+				fmt.Sprintf("Secret %q was deleted, recreating it from 1Password", secretName))
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if kubeSecrets.IsDrifted(secret) {
+		r.Recorder.Event(resource, corev1.EventTypeWarning, op.ReasonSecretDrifted,
+			fmt.Sprintf("Secret %q was modified by %q, restoring it from 1Password", secretName, kubeSecrets.LastChangedBy(secret)))
+	}
+	return nil
+}
+
 func (r *OnePasswordItemReconciler) cleanupKubernetesSecret(ctx context.Context, onePasswordItem *onepasswordv1.OnePasswordItem) error {
 	kubernetesSecret := &corev1.Secret{}
 	kubernetesSecret.Name = onePasswordItem.Name
@@ -151,6 +213,7 @@ func (r *OnePasswordItemReconciler) cleanupKubernetesSecret(ctx context.Context,
 			return err
 		}
 	}
+	op.ForgetSecret(kubernetesSecret.Namespace, kubernetesSecret.Name)
 	return nil
 }
 
@@ -164,6 +227,7 @@ func (r *OnePasswordItemReconciler) handleOnePasswordItem(ctx context.Context, r
 	labels := resource.Labels
 	secretType := resource.Type
 	autoRestart := resource.Annotations[op.AutoRestartWorkloadAnnotation]
+	categoryPreset := resource.Annotations[kubeSecrets.CategoryPresetAnnotation]
 	var annotations map[string]string
 	if r.Config.EnableAnnotations {
 		annotations = resource.Annotations
@@ -173,9 +237,24 @@ func (r *OnePasswordItemReconciler) handleOnePasswordItem(ctx context.Context, r
 
 	item, err := op.GetOnePasswordItemByPath(ctx, r.OpClient, resource.Spec.ItemPath)
 	if err != nil {
+		recordItemError(r.Recorder, resource, err)
 		return fmt.Errorf("failed to retrieve item: %w", err)
 	}
 
+	var keystore *kubeSecrets.Keystore
+	if resource.Spec.Keystore != nil {
+		keystoreItems, err := op.GetKeystoreItems(ctx, r.OpClient, resource.Spec.Keystore)
+		if err != nil {
+			recordItemError(r.Recorder, resource, err)
+			return fmt.Errorf("failed to retrieve keystore items: %w", err)
+		}
+		keystore = &kubeSecrets.Keystore{Spec: resource.Spec.Keystore, Items: keystoreItems}
+	}
+
+	if err := r.recordSecretDrift(ctx, resource); err != nil {
+		return fmt.Errorf("failed to check secret for changes: %w", err)
+	}
+
 	// Create owner reference.
 	gvk, err := apiutil.GVKForObject(resource, r.Scheme)
 	if err != nil {
@@ -188,7 +267,23 @@ func (r *OnePasswordItemReconciler) handleOnePasswordItem(ctx context.Context, r
 		UID:        resource.GetUID(),
 	}
 
-	return kubeSecrets.CreateKubernetesSecretFromItem(ctx, r.Client, secretName, resource.Namespace, item, autoRestart, labels, annotations, secretType, ownerRef, r.Config.AllowEmptyValues)
+	secret, err := kubeSecrets.CreateKubernetesSecretFromItem(ctx, r.Client, secretName, resource.Namespace, item,
+		kubeSecrets.SecretOptions{
+			AutoRestart:      autoRestart,
+			Labels:           labels,
+			Annotations:      annotations,
+			Type:             secretType,
+			OwnerRef:         ownerRef,
+			AllowEmptyValues: r.Config.AllowEmptyValues,
+			Keystore:         keystore,
+			CategoryPreset:   categoryPreset,
+		})
+	if err != nil {
+		return err
+	}
+	op.ObserveSecretSynced(resource.Namespace, secretName, item)
+	recordSecretSynced(r.Recorder, resource, secret, item, r.Config.AllowEmptyValues)
+	return nil
 }
 
 func (r *OnePasswordItemReconciler) updateStatus(ctx context.Context, resource *onepasswordv1.OnePasswordItem, err error) error {
@@ -201,6 +296,7 @@ func (r *OnePasswordItemReconciler) updateStatus(ctx context.Context, resource *
 		updatedCondition.Message = ""
 		updatedCondition.Status = metav1.ConditionTrue
 	}
+	updatedCondition.Reason = conditionReason(err)
 
 	if existingCondition.Status != updatedCondition.Status {
 		updatedCondition.LastTransitionTime = metav1.Now()
@@ -0,0 +1,101 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// resultForError decides how to requeue after syncing a secret failed with err. When 1Password
+// tells when to retry, the request is requeued at that time. Otherwise err is returned so that
+// the request is requeued by the controller's rate limiter, see newRetryRateLimiter.
+func resultForError(err error) (ctrl.Result, error) {
+	if retryAfter, ok := opclient.RetryAfter(err); ok {
+		return ctrl.Result{RequeueAfter: retryAfter}, nil
+	}
+	return ctrl.Result{}, err
+}
+
+// retryRateLimiter requeues failed requests following a retry policy, counting failures per request.
+type retryRateLimiter struct {
+	policy   retry.Policy
+	mu       sync.Mutex
+	failures map[reconcile.Request]int
+}
+
+func newRetryRateLimiter(policy retry.Policy) workqueue.TypedRateLimiter[reconcile.Request] {
+	return &retryRateLimiter{
+		policy:   policy,
+		failures: map[reconcile.Request]int{},
+	}
+}
+
+func (r *retryRateLimiter) When(request reconcile.Request) time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	failures := r.failures[request]
+	r.failures[request] = failures + 1
+	return r.policy.Delay(failures, nil)
+}
+
+func (r *retryRateLimiter) Forget(request reconcile.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.failures, request)
+}
+
+func (r *retryRateLimiter) NumRequeues(request reconcile.Request) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.failures[request]
+}
+
+// conditionReason returns the reason of the Ready condition after syncing a secret.
+func conditionReason(err error) string {
+	switch {
+	case err == nil:
+		return onepasswordv1.ReasonSynced
+	case errors.Is(err, opclient.ErrNotFound):
+		return onepasswordv1.ReasonItemNotFound
+	case errors.Is(err, opclient.ErrUnauthorized):
+		return onepasswordv1.ReasonUnauthorized
+	case errors.Is(err, opclient.ErrRateLimited):
+		return onepasswordv1.ReasonRateLimited
+	case errors.Is(err, opclient.ErrUnavailable):
+		return onepasswordv1.ReasonUnavailable
+	default:
+		return onepasswordv1.ReasonSyncFailed
+	}
+}
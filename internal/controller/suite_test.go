@@ -28,7 +28,6 @@ import (
 	"context"
 	"os"
 	"path/filepath"
-	"regexp"
 	"testing"
 	"time"
 
@@ -46,6 +45,7 @@ import (
 
 	onepasswordcomv1 "github.com/1Password/onepassword-operator/api/v1"
 	"github.com/1Password/onepassword-operator/pkg/mocks"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
 	// +kubebuilder:scaffold:imports
 )
@@ -59,8 +59,6 @@ const (
 
 	username2 = "test-user2"
 	password2 = "4zotzqDqXKasLFT2jzTs"
-
-	annotationRegExpString = "^operator\\.1password\\.io\\/[a-zA-Z\\.]+"
 )
 
 // Define utility constants for object names and testing timeouts/durations and intervals.
@@ -79,7 +77,6 @@ var (
 	ctx                       context.Context
 	cancel                    context.CancelFunc
 	onePasswordItemReconciler *OnePasswordItemReconciler
-	deploymentReconciler      *DeploymentReconciler
 	mockGetItemByIDFunc       *mock.Call
 
 	item1 = &TestItem{
@@ -191,20 +188,22 @@ var _ = BeforeSuite(func() {
 		Client:   k8sManager.GetClient(),
 		Scheme:   k8sManager.GetScheme(),
 		OpClient: mockOpClient,
+		Recorder: k8sManager.GetEventRecorderFor("onepassword-operator-onepassworditem"),
 	}
 	err = (onePasswordItemReconciler).SetupWithManager(k8sManager)
 	Expect(err).ToNot(HaveOccurred())
 
-	r, _ := regexp.Compile(annotationRegExpString)
-	deploymentReconciler = &DeploymentReconciler{
-		Client:             k8sManager.GetClient(),
-		Scheme:             k8sManager.GetScheme(),
-		OpClient:           mockOpClient,
-		OpAnnotationRegExp: r,
-		Recorder:           k8sManager.GetEventRecorderFor("onepassword-operator-deployment"),
+	for _, workload := range op.NewWorkloads() {
+		err = (&WorkloadReconciler{
+			Client:             k8sManager.GetClient(),
+			Scheme:             k8sManager.GetScheme(),
+			OpClient:           mockOpClient,
+			OpAnnotationRegExp: op.AnnotationRegExp,
+			Recorder:           k8sManager.GetEventRecorderFor("onepassword-operator-workload"),
+			Workload:           workload,
+		}).SetupWithManager(k8sManager)
+		Expect(err).ToNot(HaveOccurred())
 	}
-	err = (deploymentReconciler).SetupWithManager(k8sManager)
-	Expect(err).ToNot(HaveOccurred())
 
 	go func() {
 		defer GinkgoRecover()
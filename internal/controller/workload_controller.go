@@ -0,0 +1,300 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	"github.com/1Password/onepassword-operator/pkg/logs"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
+	"github.com/1Password/onepassword-operator/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var logWorkload = logf.Log.WithName("controller_workload")
+
+// WorkloadReconciler reconciles workloads annotated with a 1Password item path.
+// One reconciler is registered per kind, Workload is an empty object of that kind.
+type WorkloadReconciler struct {
+	client.Client
+	Scheme             *runtime.Scheme
+	OpClient           opclient.Client
+	OpAnnotationRegExp *regexp.Regexp
+	Recorder           record.EventRecorder
+	Config             ReconcilerConfig
+	Workload           client.Object
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
+// +kubebuilder:rbac:groups=apps,resources=statefulsets;daemonsets,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=apps,resources=statefulsets/finalizers;daemonsets/finalizers,verbs=update
+// +kubebuilder:rbac:groups="",resources=pods/eviction,verbs=create
+// +kubebuilder:rbac:groups=batch,resources=jobs;cronjobs,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=batch,resources=jobs/finalizers;cronjobs/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime/pkg/reconcile
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	ctx, span := tracing.Start(ctx, "WorkloadReconciler.Reconcile", attribute.String("k8s.object.kind", r.kind()),
+		attribute.String("k8s.namespace.name", req.Namespace), attribute.String("k8s.object.name", req.Name))
+	result, err := r.reconcile(ctx, req)
+	tracing.End(span, err)
+	return result, err
+}
+
+func (r *WorkloadReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	kind := r.kind()
+	reqLogger := logWorkload.WithValues("Kind", kind, "Request.Namespace", req.Namespace, "Request.Name", req.Name)
+	reqLogger.V(logs.DebugLevel).Info(fmt.Sprintf("Reconciling %s", kind))
+
+	workload, ok := r.Workload.DeepCopyObject().(client.Object)
+	if !ok {
+		return ctrl.Result{}, fmt.Errorf("unsupported workload type %T", r.Workload)
+	}
+	err := r.Get(ctx, req.NamespacedName, workload)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Jobs created from a CronJob inherit its pod template, the CronJob owns the secret.
+	if isCreatedByCronJob(workload) {
+		return ctrl.Result{}, nil
+	}
+
+	annotations, annotationsFound := op.GetAnnotationsForWorkload(workload, r.OpAnnotationRegExp)
+	if !annotationsFound {
+		reqLogger.V(logs.DebugLevel).Info("No 1Password Annotations found")
+		return ctrl.Result{}, nil
+	}
+
+	// If the workload is not being deleted
+	if workload.GetDeletionTimestamp().IsZero() {
+		// Adds a finalizer to the workload if one does not exist.
+		// This is so we can handle cleanup of associated secrets properly
+		if !utils.ContainsString(workload.GetFinalizers(), finalizer) {
+			workload.SetFinalizers(append(workload.GetFinalizers(), finalizer))
+			if err = r.Update(ctx, workload); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		// Handles creation or updating secrets for workload if needed
+		if err = r.handleApplyingWorkload(ctx, workload, annotations, req); err != nil {
+			op.ObserveSecretSyncFailed(req.Namespace)
+			result, resultErr := resultForError(err)
+			if conditionReason(err) == onepasswordv1.ReasonRateLimited {
+				message := "1Password rate limit hit. Retrying with backoff."
+				if resultErr == nil {
+					message = fmt.Sprintf("1Password rate limit hit. Requeuing after %s.", result.RequeueAfter)
+				}
+				reqLogger.V(logs.InfoLevel).Info(message)
+				r.Recorder.Event(workload, corev1.EventTypeWarning, onepasswordv1.ReasonRateLimited, message)
+				return result, resultErr
+			}
+			r.Recorder.Event(workload, corev1.EventTypeWarning, "ReconcileError", fmt.Sprintf("Failed to sync secret from 1Password: %s", err.Error()))
+			return result, resultErr
+		}
+		return ctrl.Result{}, nil
+	}
+	// The workload has been marked for deletion. If the one password
+	// finalizer is found there are cleanup tasks to perform
+	if utils.ContainsString(workload.GetFinalizers(), finalizer) {
+
+		secretName := annotations[op.NameAnnotation]
+		if err = r.cleanupKubernetesSecretForWorkload(ctx, secretName, workload); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		// Remove the finalizer from the workload so deletion of workload can be completed
+		if err = r.removeOnePasswordFinalizerFromWorkload(ctx, workload); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.Workload).
+		Named("onepassword-" + strings.ToLower(r.kind())).
+		WithOptions(controller.Options{RateLimiter: newRetryRateLimiter(r.Config.retryPolicy())}).
+		Complete(r)
+}
+
+func (r *WorkloadReconciler) kind() string {
+	gvk, err := apiutil.GVKForObject(r.Workload, r.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", r.Workload)
+	}
+	return gvk.Kind
+}
+
+func isCreatedByCronJob(workload client.Object) bool {
+	if _, ok := workload.(*batchv1.Job); !ok {
+		return false
+	}
+	owner := metav1.GetControllerOf(workload)
+	return owner != nil && owner.Kind == "CronJob"
+}
+
+func (r *WorkloadReconciler) cleanupKubernetesSecretForWorkload(ctx context.Context, secretName string, deletedWorkload client.Object) error {
+	kubernetesSecret := &corev1.Secret{}
+	kubernetesSecret.Name = secretName
+	kubernetesSecret.Namespace = deletedWorkload.GetNamespace()
+
+	if len(secretName) == 0 {
+		return nil
+	}
+	updatedSecrets := map[string]*corev1.Secret{secretName: kubernetesSecret}
+
+	multipleWorkloadsUsingSecret, err := r.areMultipleWorkloadsUsingSecret(ctx, updatedSecrets, deletedWorkload)
+	if err != nil {
+		return err
+	}
+
+	// Only delete the associated kubernetes secret if it is not being used by other workloads
+	if !multipleWorkloadsUsingSecret {
+		if err = r.Delete(ctx, kubernetesSecret); err != nil {
+			if !errors.IsNotFound(err) {
+				return err
+			}
+		}
+		op.ForgetSecret(kubernetesSecret.Namespace, kubernetesSecret.Name)
+	}
+	return nil
+}
+
+// areMultipleWorkloadsUsingSecret checks the workloads of every supported kind in the namespace,
+// so a secret shared between e.g. a Deployment and a CronJob outlives either of them.
+func (r *WorkloadReconciler) areMultipleWorkloadsUsingSecret(ctx context.Context, updatedSecrets map[string]*corev1.Secret, deletedWorkload client.Object) (bool, error) {
+	opts := []client.ListOption{
+		client.InNamespace(deletedWorkload.GetNamespace()),
+	}
+
+	for _, list := range op.NewWorkloadLists() {
+		err := r.List(ctx, list, opts...)
+		if err != nil {
+			logWorkload.Error(err, fmt.Sprintf("Failed to list kubernetes workloads of type %T", list))
+			return false, err
+		}
+
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return false, err
+		}
+		for _, item := range items {
+			workload, ok := item.(client.Object)
+			if !ok || workload.GetUID() == deletedWorkload.GetUID() || isCreatedByCronJob(workload) {
+				continue
+			}
+			if op.IsWorkloadUsingSecrets(workload, updatedSecrets) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func (r *WorkloadReconciler) removeOnePasswordFinalizerFromWorkload(ctx context.Context, workload client.Object) error {
+	workload.SetFinalizers(utils.RemoveString(workload.GetFinalizers(), finalizer))
+	return r.Update(ctx, workload)
+}
+
+func (r *WorkloadReconciler) handleApplyingWorkload(ctx context.Context, workload client.Object, annotations map[string]string, request reconcile.Request) error {
+	reqLog := logWorkload.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+
+	secretName := annotations[op.NameAnnotation]
+	secretLabels := map[string]string(nil)
+	secretType := string(corev1.SecretTypeOpaque)
+
+	if len(secretName) == 0 {
+		reqLog.Info("No 'item-name' annotation set. 'item-path' and 'item-name' must be set as annotations to add new secret.")
+		return nil
+	}
+
+	item, err := op.GetOnePasswordItemByPath(ctx, r.OpClient, annotations[op.ItemPathAnnotation])
+	if err != nil {
+		recordItemError(r.Recorder, workload, err)
+		return fmt.Errorf("failed to retrieve item: %w", err)
+	}
+
+	// Create owner reference.
+	gvk, err := apiutil.GVKForObject(workload, r.Scheme)
+	if err != nil {
+		return fmt.Errorf("could not to retrieve group version kind: %w", err)
+	}
+	ownerRef := &metav1.OwnerReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Name:       workload.GetName(),
+		UID:        workload.GetUID(),
+	}
+
+	secret, err := kubeSecrets.CreateKubernetesSecretFromItem(ctx, r.Client, secretName, workload.GetNamespace(), item,
+		kubeSecrets.SecretOptions{
+			AutoRestart:      annotations[op.AutoRestartWorkloadAnnotation],
+			Labels:           secretLabels,
+			Annotations:      annotations,
+			Type:             secretType,
+			OwnerRef:         ownerRef,
+			AllowEmptyValues: r.Config.AllowEmptyValues,
+			CategoryPreset:   annotations[kubeSecrets.CategoryPresetAnnotation],
+		})
+	if err != nil {
+		return err
+	}
+	op.ObserveSecretSynced(workload.GetNamespace(), secretName, item)
+	recordSecretSynced(r.Recorder, workload, secret, item, r.Config.AllowEmptyValues)
+	return nil
+}
@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+)
+
+const cronJobName = "test-cronjob"
+
+var _ = Describe("Workload controller", func() {
+	ctx := context.Background()
+	var cronJobKey types.NamespacedName
+	var secretKey types.NamespacedName
+
+	makeCronJob := func() {
+		cronJobKey = types.NamespacedName{
+			Name:      cronJobName,
+			Namespace: namespace,
+		}
+
+		secretKey = types.NamespacedName{
+			Name:      item1.Name,
+			Namespace: namespace,
+		}
+
+		By("Creating a CronJob with pod template annotations successfully")
+		cronJob := &batchv1.CronJob{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      cronJobKey.Name,
+				Namespace: cronJobKey.Namespace,
+			},
+			Spec: batchv1.CronJobSpec{
+				Schedule: "0 0 * * *",
+				JobTemplate: batchv1.JobTemplateSpec{
+					Spec: batchv1.JobSpec{
+						Template: v1.PodTemplateSpec{
+							ObjectMeta: metav1.ObjectMeta{
+								Annotations: map[string]string{
+									op.ItemPathAnnotation: item1.Path,
+									op.NameAnnotation:     item1.Name,
+								},
+							},
+							Spec: v1.PodSpec{
+								RestartPolicy: v1.RestartPolicyNever,
+								Containers: []v1.Container{
+									{
+										Name:  cronJobName,
+										Image: "busybox",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cronJob)).Should(Succeed())
+
+		By("Creating the K8s secret successfully")
+		createdSecret := &v1.Secret{}
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, secretKey, createdSecret)
+			return err == nil
+		}, timeout, interval).Should(BeTrue())
+		Expect(createdSecret.Data).Should(Equal(item1.SecretData))
+		Expect(createdSecret.OwnerReferences).Should(HaveLen(1))
+		Expect(createdSecret.OwnerReferences[0].Kind).Should(Equal("CronJob"))
+	}
+
+	cleanK8sResources := func() {
+		err := k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = k8sClient.DeleteAllOf(ctx, &appsv1.Deployment{}, client.InNamespace(namespace))
+		Expect(err).ToNot(HaveOccurred())
+
+		err = k8sClient.DeleteAllOf(ctx, &v1.Secret{}, client.InNamespace(namespace))
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	deleteCronJob := func() {
+		Eventually(func() error {
+			f := &batchv1.CronJob{}
+			err := k8sClient.Get(ctx, cronJobKey, f)
+			if err != nil {
+				return err
+			}
+			return k8sClient.Delete(ctx, f)
+		}, timeout, interval).Should(Succeed())
+
+		Eventually(func() error {
+			f := &batchv1.CronJob{}
+			return k8sClient.Get(ctx, cronJobKey, f)
+		}, timeout, interval).ShouldNot(Succeed())
+	}
+
+	BeforeEach(func() {
+		cleanK8sResources()
+		mockGetItemByIDFunc.Return(item1.ToModel(), nil)
+		time.Sleep(time.Second)
+		makeCronJob()
+	})
+
+	AfterEach(func() {
+		cleanK8sResources()
+	})
+
+	Context("CronJob with secrets from 1Password", func() {
+		It("Should add the finalizer to the CronJob", func() {
+			Eventually(func() []string {
+				f := &batchv1.CronJob{}
+				if err := k8sClient.Get(ctx, cronJobKey, f); err != nil {
+					return nil
+				}
+				return f.Finalizers
+			}, timeout, interval).Should(ContainElement(finalizer))
+		})
+
+		It("Should delete secret if CronJob is deleted", func() {
+			deleteCronJob()
+
+			Eventually(func() error {
+				f := &v1.Secret{}
+				return k8sClient.Get(ctx, secretKey, f)
+			}, timeout, interval).ShouldNot(Succeed())
+		})
+
+		It("Should keep secret if a Deployment still uses it", func() {
+			By("Creating a Deployment referencing the secret")
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "cronjob-sibling",
+					Namespace: namespace,
+				},
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "cronjob-sibling"},
+					},
+					Template: v1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{
+							Labels: map[string]string{"app": "cronjob-sibling"},
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name:  "cronjob-sibling",
+									Image: "busybox",
+									EnvFrom: []v1.EnvFromSource{
+										{
+											SecretRef: &v1.SecretEnvSource{
+												LocalObjectReference: v1.LocalObjectReference{Name: item1.Name},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}
+			Expect(k8sClient.Create(ctx, deployment)).Should(Succeed())
+
+			deleteCronJob()
+
+			Consistently(func() error {
+				f := &v1.Secret{}
+				return k8sClient.Get(ctx, secretKey, f)
+			}, time.Second*2, interval).Should(Succeed())
+		})
+	})
+})
@@ -0,0 +1,164 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+)
+
+// SetupOnePasswordItemWebhookWithManager registers the webhook for OnePasswordItem in the manager.
+func SetupOnePasswordItemWebhookWithManager(mgr ctrl.Manager, config ValidatorConfig) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&onepasswordv1.OnePasswordItem{}).
+		WithValidator(&OnePasswordItemCustomValidator{Client: mgr.GetClient(), Config: config}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-onepassword-com-v1-onepassworditem,mutating=false,failurePolicy=fail,sideEffects=None,groups=onepassword.com,resources=onepassworditems,verbs=create;update,versions=v1,name=vonepassworditem-v1.onepassword.com,admissionReviewVersions=v1
+
+// OnePasswordItemCustomValidator rejects OnePasswordItems the reconciler would fail on.
+type OnePasswordItemCustomValidator struct {
+	Client client.Reader
+	Config ValidatorConfig
+}
+
+var _ admission.CustomValidator = &OnePasswordItemCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type OnePasswordItem.
+func (v *OnePasswordItemCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	item, ok := obj.(*onepasswordv1.OnePasswordItem)
+	if !ok {
+		return nil, fmt.Errorf("expected a OnePasswordItem object but got %T", obj)
+	}
+	return nil, v.validate(ctx, item, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type OnePasswordItem.
+func (v *OnePasswordItemCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldItem, ok := oldObj.(*onepasswordv1.OnePasswordItem)
+	if !ok {
+		return nil, fmt.Errorf("expected a OnePasswordItem object for the oldObj but got %T", oldObj)
+	}
+	item, ok := newObj.(*onepasswordv1.OnePasswordItem)
+	if !ok {
+		return nil, fmt.Errorf("expected a OnePasswordItem object for the newObj but got %T", newObj)
+	}
+	return nil, v.validate(ctx, item, oldItem)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type OnePasswordItem.
+func (v *OnePasswordItemCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *OnePasswordItemCustomValidator) validate(
+	ctx context.Context,
+	item, oldItem *onepasswordv1.OnePasswordItem,
+) error {
+	// Objects being deleted only get their finalizers removed.
+	if !item.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	itemPathField := field.NewPath("spec", "itemPath")
+	if err := validateItemPath(itemPathField, item.Spec.ItemPath); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if item.Spec.Keystore != nil {
+		for i, path := range item.Spec.Keystore.ItemPaths {
+			if err := validateItemPath(field.NewPath("spec", "keystore", "itemPaths").Index(i), path); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+	}
+
+	annotationsField := field.NewPath("metadata", "annotations")
+	for _, key := range []string{op.AutoRestartWorkloadAnnotation, kubeSecrets.CategoryPresetAnnotation} {
+		if err := validateBoolAnnotation(annotationsField, item.Annotations, key); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	typeField := field.NewPath("type")
+	if oldItem != nil && secretType(oldItem.Type) != secretType(item.Type) {
+		allErrs = append(allErrs, field.Forbidden(typeField, kubeSecrets.ErrCannotUpdateSecretType.Error()))
+	}
+
+	secretName := kubeSecrets.FormatSecretName(item.Name)
+	if oldItem == nil {
+		secret := &corev1.Secret{}
+		err := v.Client.Get(ctx, client.ObjectKey{Namespace: item.Namespace, Name: secretName}, secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && secretType(string(secret.Type)) != secretType(item.Type) {
+			allErrs = append(allErrs, field.Forbidden(typeField, fmt.Sprintf(
+				"Secret %q already exists with type %q: %s", secretName, secret.Type, kubeSecrets.ErrCannotUpdateSecretType)))
+		}
+	}
+
+	owners, err := findSecretOwners(ctx, v.Client, item.Namespace, secretName)
+	if err != nil {
+		return err
+	}
+	for _, owner := range owners {
+		if owner.uid != item.UID || owner.kind != "OnePasswordItem" {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata", "name"), fmt.Sprintf(
+				"Secret %q is already generated for %s", secretName, owner)))
+		}
+	}
+
+	if len(allErrs) == 0 && (oldItem == nil || oldItem.Spec.ItemPath != item.Spec.ItemPath) {
+		if err := v.Config.validateItemExists(ctx, itemPathField, item.Spec.ItemPath); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(onepasswordv1.GroupVersion.WithKind("OnePasswordItem").GroupKind(), item.Name, allErrs)
+}
+
+// secretType treats "" and Opaque as the same type, as Kubernetes does.
+func secretType(t string) string {
+	if t == "" {
+		return string(corev1.SecretTypeOpaque)
+	}
+	return t
+}
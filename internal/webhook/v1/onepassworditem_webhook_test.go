@@ -0,0 +1,135 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const (
+	testNamespace = "default"
+	testItemPath  = "vaults/hfnjvi6aymbsnfc2xeeoheizda/items/nwrhuano7bcwddcviubpp4mhfq"
+)
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	t.Helper()
+	s := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(s))
+	require.NoError(t, onepasswordv1.AddToScheme(s))
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func newOnePasswordItem(name, itemPath string) *onepasswordv1.OnePasswordItem {
+	return &onepasswordv1.OnePasswordItem{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, UID: "item-uid"},
+		Spec:       onepasswordv1.OnePasswordItemSpec{ItemPath: itemPath},
+	}
+}
+
+func TestOnePasswordItemValidateCreate(t *testing.T) {
+	testCases := map[string]struct {
+		item          *onepasswordv1.OnePasswordItem
+		existing      []client.Object
+		expectedError string
+	}{
+		"valid item": {
+			item: newOnePasswordItem("secret", testItemPath),
+		},
+		"malformed item path": {
+			item:          newOnePasswordItem("secret", "vaults/vault/item"),
+			expectedError: "spec.itemPath: Invalid value: \"vaults/vault/item\"",
+		},
+		"missing item path": {
+			item:          newOnePasswordItem("secret", ""),
+			expectedError: "spec.itemPath: Required value",
+		},
+		"non-bool auto-restart annotation": {
+			item: func() *onepasswordv1.OnePasswordItem {
+				item := newOnePasswordItem("secret", testItemPath)
+				item.Annotations = map[string]string{op.AutoRestartWorkloadAnnotation: "yes please"}
+				return item
+			}(),
+			expectedError: "metadata.annotations[operator.1password.io/auto-restart]: Invalid value: \"yes please\": must be true or false",
+		},
+		"existing secret with another type": {
+			item: newOnePasswordItem("secret", testItemPath),
+			existing: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: testNamespace},
+				Type:       corev1.SecretTypeDockerConfigJson,
+			}},
+			expectedError: "type: Forbidden: Secret \"secret\" already exists with type \"kubernetes.io/dockerconfigjson\"",
+		},
+		"secret already generated for a workload": {
+			item: newOnePasswordItem("secret", testItemPath),
+			existing: []client.Object{&appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "app",
+					Namespace: testNamespace,
+					Annotations: map[string]string{
+						op.ItemPathAnnotation: testItemPath,
+						op.NameAnnotation:     "secret",
+					},
+				},
+			}},
+			expectedError: "Secret \"secret\" is already generated for Deployment \"app\"",
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			validator := &OnePasswordItemCustomValidator{Client: newFakeClient(t, tc.existing...)}
+			_, err := validator.ValidateCreate(context.Background(), tc.item)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, apierrors.IsInvalid(err), "expected an Invalid error, got %v", err)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestOnePasswordItemValidateUpdateRejectsTypeChange(t *testing.T) {
+	oldItem := newOnePasswordItem("secret", testItemPath)
+	item := oldItem.DeepCopy()
+	item.Type = string(corev1.SecretTypeBasicAuth)
+
+	validator := &OnePasswordItemCustomValidator{Client: newFakeClient(t, oldItem)}
+	_, err := validator.ValidateUpdate(context.Background(), oldItem, item)
+	require.ErrorContains(t, err, "type: Forbidden: cannot change secret type")
+
+	// Opaque and "" are the same type.
+	item.Type = string(corev1.SecretTypeOpaque)
+	_, err = validator.ValidateUpdate(context.Background(), oldItem, item)
+	require.NoError(t, err)
+}
+
+func TestOnePasswordItemValidateOnline(t *testing.T) {
+	opClient := &mocks.TestClient{}
+	opClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+	opClient.On("GetItemByID", mock.Anything, mock.Anything).Return(nil, errors.New("not found"))
+	opClient.On("GetItemsByTitle", mock.Anything, mock.Anything).Return([]model.Item{}, nil)
+
+	validator := &OnePasswordItemCustomValidator{
+		Client: newFakeClient(t),
+		Config: ValidatorConfig{OpClient: opClient},
+	}
+	_, err := validator.ValidateCreate(context.Background(), newOnePasswordItem("secret", testItemPath))
+	require.ErrorContains(t, err, "item \"nwrhuano7bcwddcviubpp4mhfq\" not found")
+}
@@ -0,0 +1,215 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/1Password/onepassword-operator/pkg/injector"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	"github.com/1Password/onepassword-operator/version"
+)
+
+const (
+	// InjectionStatusInjected is the value of the status annotation on mutated pods.
+	InjectionStatusInjected = "injected"
+
+	binVolumeName       = "op-bin"
+	binMountPath        = "/op/bin"
+	binPath             = binMountPath + "/" + injector.BinaryName
+	copyBinInitName     = "copy-op-bin"
+	injectorImageBinary = "/manager"
+
+	tokenVolumeName = "op-token"
+	// tokenExpirationSeconds is the shortest lifetime the API server accepts. The token is only
+	// used once, when the container starts.
+	tokenExpirationSeconds = int64(600)
+)
+
+var podlog = logf.Log.WithName("pod-resource")
+
+// DefaultInjectorImage is the operator image the injector is copied from.
+var DefaultInjectorImage = "1password/onepassword-operator:" + version.OperatorVersion
+
+// PodInjectorConfig configures the env var injection webhook.
+type PodInjectorConfig struct {
+	// Image is the operator image the injector is copied from.
+	Image string
+	// ResolveURL is the URL of the operator endpoint resolving op:// references.
+	ResolveURL string
+	// CAFile is the file holding the CA of the webhook certificate, which injected containers trust
+	// for ResolveURL. It is read on every injection to pick up a rotated CA. Without it, injected
+	// containers trust the system CAs.
+	CAFile string
+}
+
+// SetupPodWebhookWithManager registers the webhook for Pod in the manager.
+func SetupPodWebhookWithManager(mgr ctrl.Manager, config PodInjectorConfig) error {
+	if config.Image == "" {
+		config.Image = DefaultInjectorImage
+	}
+	if config.ResolveURL == "" {
+		return errors.New("the URL resolving op:// references must be set")
+	}
+	return ctrl.NewWebhookManagedBy(mgr).For(&corev1.Pod{}).
+		WithDefaulter(&PodCustomDefaulter{Config: config}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate--v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,groups="",resources=pods,verbs=create,versions=v1,name=mpod-v1.onepassword.com,admissionReviewVersions=v1
+
+// PodCustomDefaulter injects the operator's op:// reference resolver into pods labeled
+// operator.1password.io/inject=true and annotated with operator.1password.io/inject.
+//
+// An init container copies the operator binary into the pod, and the command of the containers listed
+// in the annotation is wrapped with it. When the container starts, it requests the values of the env
+// vars holding op:// references from the operator with a token bound to the pod, and runs the command
+// with them. The operator resolves them with its own 1Password client, so pods need no 1Password
+// credentials, and the values never pass through the Kubernetes API.
+type PodCustomDefaulter struct {
+	Config PodInjectorConfig
+}
+
+var _ admission.CustomDefaulter = &PodCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind Pod.
+func (d *PodCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return fmt.Errorf("expected a Pod object but got %T", obj)
+	}
+
+	if pod.Labels[op.InjectLabel] != op.InjectLabelValue {
+		return nil
+	}
+	containerNames := parseInjectAnnotation(pod.Annotations[op.InjectAnnotation])
+	if len(containerNames) == 0 || pod.Annotations[op.InjectionStatusAnnotation] == InjectionStatusInjected {
+		return nil
+	}
+	podName := pod.GetName()
+	if podName == "" {
+		podName = pod.GetGenerateName()
+	}
+	reqLog := podlog.WithValues("Pod.Namespace", pod.GetNamespace(), "Pod.Name", podName)
+
+	var ca []byte
+	if d.Config.CAFile != "" {
+		var err error
+		if ca, err = os.ReadFile(d.Config.CAFile); err != nil && !os.IsNotExist(err) {
+			reqLog.Error(err, "Failed to read the webhook CA, injected containers use the system CAs")
+		}
+	}
+
+	injected := false
+	for i := range pod.Spec.Containers {
+		container := &pod.Spec.Containers[i]
+		if !slices.Contains(containerNames, container.Name) || !hasSecretReferences(container.Env) {
+			continue
+		}
+		if len(container.Command) == 0 {
+			reqLog.Info(fmt.Sprintf("Skipping container %q: a command must be set to inject the op CLI", container.Name))
+			continue
+		}
+
+		container.Command = append([]string{binPath, injector.RunCommand, "--"}, container.Command...)
+		container.VolumeMounts = append(container.VolumeMounts,
+			corev1.VolumeMount{Name: binVolumeName, MountPath: binMountPath, ReadOnly: true},
+			corev1.VolumeMount{Name: tokenVolumeName, MountPath: injector.TokenMountPath, ReadOnly: true},
+		)
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: injector.URLEnv, Value: d.Config.ResolveURL},
+			corev1.EnvVar{Name: injector.ContainerEnv, Value: container.Name},
+		)
+		if len(ca) > 0 {
+			container.Env = append(container.Env, corev1.EnvVar{Name: injector.CAEnv, Value: string(ca)})
+		}
+		injected = true
+	}
+	if !injected {
+		return nil
+	}
+
+	expirationSeconds := tokenExpirationSeconds
+	pod.Spec.Volumes = append(pod.Spec.Volumes,
+		corev1.Volume{
+			Name:         binVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{Medium: corev1.StorageMediumMemory}},
+		},
+		corev1.Volume{
+			Name: tokenVolumeName,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+					Audience:          injector.TokenAudience,
+					ExpirationSeconds: &expirationSeconds,
+					Path:              injector.TokenFile,
+				}}},
+			}},
+		},
+	)
+	pod.Spec.InitContainers = append([]corev1.Container{{
+		Name:            copyBinInitName,
+		Image:           d.Config.Image,
+		ImagePullPolicy: corev1.PullIfNotPresent,
+		Command:         []string{injectorImageBinary, injector.InstallCommand, binPath},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name:      binVolumeName,
+			MountPath: binMountPath,
+		}},
+	}}, pod.Spec.InitContainers...)
+
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[op.InjectionStatusAnnotation] = InjectionStatusInjected
+	reqLog.Info("Injected op:// reference resolver into pod")
+	return nil
+}
+
+func parseInjectAnnotation(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func hasSecretReferences(env []corev1.EnvVar) bool {
+	return slices.ContainsFunc(env, func(e corev1.EnvVar) bool {
+		return strings.HasPrefix(e.Value, injector.ReferencePrefix)
+	})
+}
@@ -0,0 +1,142 @@
+package v1
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/1Password/onepassword-operator/pkg/injector"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+)
+
+func newTestPod(annotations map[string]string, env ...corev1.EnvVar) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Labels:      map[string]string{op.InjectLabel: op.InjectLabelValue},
+			Annotations: annotations,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    "app",
+					Image:   "app:latest",
+					Command: []string{"/app"},
+					Env:     env,
+				},
+				{
+					Name:    "sidecar",
+					Image:   "sidecar:latest",
+					Command: []string{"/sidecar"},
+					Env:     env,
+				},
+			},
+		},
+	}
+}
+
+var (
+	secretRefEnv = corev1.EnvVar{Name: "DB_PASSWORD", Value: "op://vault/item/password"}
+	plainEnv     = corev1.EnvVar{Name: "DB_USER", Value: "app"}
+)
+
+const testResolveURL = "https://webhook-service.onepassword.svc/resolve"
+
+func TestPodCustomDefaulter(t *testing.T) {
+	testCases := map[string]struct {
+		pod              *corev1.Pod
+		expectedInjected bool
+	}{
+		"pod without annotation is not changed": {
+			pod: newTestPod(nil, secretRefEnv),
+		},
+		"pod without label is not changed": {
+			pod: func() *corev1.Pod {
+				pod := newTestPod(map[string]string{op.InjectAnnotation: "app"}, secretRefEnv)
+				pod.Labels = nil
+				return pod
+			}(),
+		},
+		"container without op:// references is not changed": {
+			pod: newTestPod(map[string]string{op.InjectAnnotation: "app"}, plainEnv),
+		},
+		"annotated container is injected": {
+			pod:              newTestPod(map[string]string{op.InjectAnnotation: "app"}, secretRefEnv, plainEnv),
+			expectedInjected: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			defaulter := &PodCustomDefaulter{Config: PodInjectorConfig{
+				Image:      DefaultInjectorImage,
+				ResolveURL: testResolveURL,
+			}}
+			original := tc.pod.DeepCopy()
+			require.NoError(t, defaulter.Default(context.Background(), tc.pod))
+
+			if !tc.expectedInjected {
+				require.Equal(t, original, tc.pod)
+				return
+			}
+
+			require.Equal(t, InjectionStatusInjected, tc.pod.Annotations[op.InjectionStatusAnnotation])
+			require.Len(t, tc.pod.Spec.InitContainers, 1)
+			initContainer := tc.pod.Spec.InitContainers[0]
+			require.Equal(t, copyBinInitName, initContainer.Name)
+			require.Equal(t, DefaultInjectorImage, initContainer.Image)
+			require.Equal(t, []string{"/manager", injector.InstallCommand, binPath}, initContainer.Command)
+			require.Len(t, tc.pod.Spec.Volumes, 2)
+			require.Equal(t, binVolumeName, tc.pod.Spec.Volumes[0].Name)
+			token := tc.pod.Spec.Volumes[1].Projected.Sources[0].ServiceAccountToken
+			require.Equal(t, injector.TokenAudience, token.Audience)
+
+			app := tc.pod.Spec.Containers[0]
+			require.Equal(t, []string{binPath, "run", "--", "/app"}, app.Command)
+			require.Contains(t, app.VolumeMounts, corev1.VolumeMount{Name: binVolumeName, MountPath: binMountPath, ReadOnly: true})
+			require.Contains(t, app.VolumeMounts,
+				corev1.VolumeMount{Name: tokenVolumeName, MountPath: injector.TokenMountPath, ReadOnly: true})
+			require.Equal(t, []corev1.EnvVar{
+				secretRefEnv,
+				plainEnv,
+				{Name: injector.URLEnv, Value: testResolveURL},
+				{Name: injector.ContainerEnv, Value: "app"},
+			}, app.Env)
+
+			// Only containers listed in the annotation are wrapped.
+			require.Equal(t, original.Spec.Containers[1], tc.pod.Spec.Containers[1])
+		})
+	}
+}
+
+func TestPodCustomDefaulterIsIdempotent(t *testing.T) {
+	defaulter := &PodCustomDefaulter{Config: PodInjectorConfig{Image: DefaultInjectorImage, ResolveURL: testResolveURL}}
+	pod := newTestPod(map[string]string{op.InjectAnnotation: "app,sidecar"}, secretRefEnv)
+
+	require.NoError(t, defaulter.Default(context.Background(), pod))
+	injected := pod.DeepCopy()
+	require.NoError(t, defaulter.Default(context.Background(), pod))
+	require.Equal(t, injected, pod)
+	require.Equal(t, []string{binPath, "run", "--", "/sidecar"}, pod.Spec.Containers[1].Command)
+	require.Contains(t, pod.Spec.Containers[1].Env, corev1.EnvVar{Name: injector.ContainerEnv, Value: "sidecar"})
+}
+
+func TestPodCustomDefaulterSetsCA(t *testing.T) {
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	require.NoError(t, os.WriteFile(caFile, []byte("-----BEGIN CERTIFICATE-----"), 0o600))
+	defaulter := &PodCustomDefaulter{Config: PodInjectorConfig{
+		Image:      DefaultInjectorImage,
+		ResolveURL: testResolveURL,
+		CAFile:     caFile,
+	}}
+	pod := newTestPod(map[string]string{op.InjectAnnotation: "app"}, secretRefEnv)
+
+	require.NoError(t, defaulter.Default(context.Background(), pod))
+	require.Contains(t, pod.Spec.Containers[0].Env,
+		corev1.EnvVar{Name: injector.CAEnv, Value: "-----BEGIN CERTIFICATE-----"})
+}
@@ -0,0 +1,218 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/1Password/onepassword-operator/pkg/injector"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const (
+	serviceAccountUserPrefix = "system:serviceaccount:"
+	podNameExtra             = "authentication.kubernetes.io/pod-name"
+	podUIDExtra              = "authentication.kubernetes.io/pod-uid"
+
+	maxResolveRequestSize = 1 << 20
+)
+
+// +kubebuilder:rbac:groups=authentication.k8s.io,resources=tokenreviews,verbs=create
+
+// SetupReferenceResolverWithManager registers the endpoint resolving op:// references for injected pods
+// on the webhook server of the manager, which serves it with the webhook certificate.
+func SetupReferenceResolverWithManager(mgr ctrl.Manager, opClient opclient.Client) {
+	mgr.GetWebhookServer().Register(injector.ResolvePath, &ReferenceResolver{
+		Client:   mgr.GetClient(),
+		Reader:   mgr.GetAPIReader(),
+		OpClient: opClient,
+	})
+}
+
+// ReferenceResolver resolves the op:// references in the env of containers injected by PodCustomDefaulter.
+//
+// Pods authenticate with a service account token bound to the pod. Only the references in the spec of
+// the requesting container are resolved, and only for containers listed in its inject annotation.
+type ReferenceResolver struct {
+	// Client reviews the tokens of requesting pods.
+	Client client.Client
+	// Reader gets the requesting pods. It reads from the API server, to not cache every pod of the cluster.
+	Reader client.Reader
+	// OpClient resolves the references.
+	OpClient opclient.Client
+}
+
+var _ http.Handler = &ReferenceResolver{}
+
+// ServeHTTP implements http.Handler.
+func (r *ReferenceResolver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	token, found := strings.CutPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if !found || token == "" {
+		http.Error(w, "missing bearer token", http.StatusUnauthorized)
+		return
+	}
+	var request injector.ResolveRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxResolveRequestSize)).Decode(&request); err != nil {
+		http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := req.Context()
+	pod, err := r.authenticate(ctx, token)
+	if err != nil {
+		podlog.Error(err, "Failed to authenticate request to resolve op:// references")
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	reqLog := podlog.WithValues("Pod.Namespace", pod.Namespace, "Pod.Name", pod.Name, "Container", request.Container)
+	if err := authorize(pod, request); err != nil {
+		reqLog.Info("Refused to resolve op:// references: " + err.Error())
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	values, err := r.resolve(ctx, request.References)
+	if err != nil {
+		reqLog.Error(err, "Failed to resolve op:// references")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	reqLog.Info(fmt.Sprintf("Resolved %d op:// references", len(values)))
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(injector.ResolveResponse{Values: values}); err != nil {
+		reqLog.Error(err, "Failed to write resolved op:// references")
+	}
+}
+
+// authenticate reviews the token and returns the pod it is bound to.
+func (r *ReferenceResolver) authenticate(ctx context.Context, token string) (*corev1.Pod, error) {
+	review := &authenticationv1.TokenReview{Spec: authenticationv1.TokenReviewSpec{
+		Token:     token,
+		Audiences: []string{injector.TokenAudience},
+	}}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return nil, fmt.Errorf("failed to review token: %w", err)
+	}
+	status := review.Status
+	if !status.Authenticated {
+		return nil, fmt.Errorf("token is not authenticated: %s", status.Error)
+	}
+	if !slices.Contains(status.Audiences, injector.TokenAudience) {
+		return nil, fmt.Errorf("token is not valid for audience %q", injector.TokenAudience)
+	}
+
+	serviceAccount, found := strings.CutPrefix(status.User.Username, serviceAccountUserPrefix)
+	namespace, _, _ := strings.Cut(serviceAccount, ":")
+	podName := status.User.Extra[podNameExtra]
+	podUID := status.User.Extra[podUIDExtra]
+	if !found || namespace == "" || len(podName) != 1 || len(podUID) != 1 {
+		return nil, fmt.Errorf("token of %q is not bound to a pod", status.User.Username)
+	}
+
+	pod := &corev1.Pod{}
+	if err := r.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: podName[0]}, pod); err != nil {
+		return nil, fmt.Errorf("failed to get pod %s/%s: %w", namespace, podName[0], err)
+	}
+	if string(pod.UID) != podUID[0] {
+		return nil, fmt.Errorf("token is bound to a deleted pod %s/%s", namespace, podName[0])
+	}
+	return pod, nil
+}
+
+// authorize checks that the references are those in the env of an injected container of the pod.
+func authorize(pod *corev1.Pod, request injector.ResolveRequest) error {
+	if pod.Annotations[op.InjectionStatusAnnotation] != InjectionStatusInjected ||
+		!slices.Contains(parseInjectAnnotation(pod.Annotations[op.InjectAnnotation]), request.Container) {
+		return fmt.Errorf("container %q is not injected", request.Container)
+	}
+	i := slices.IndexFunc(pod.Spec.Containers, func(c corev1.Container) bool { return c.Name == request.Container })
+	if i < 0 {
+		return fmt.Errorf("container %q not found", request.Container)
+	}
+	env := pod.Spec.Containers[i].Env
+	for name, reference := range request.References {
+		if !slices.Contains(env, corev1.EnvVar{Name: name, Value: reference}) {
+			return fmt.Errorf("env var %s of container %q does not hold %s",
+				name, request.Container, reference)
+		}
+	}
+	return nil
+}
+
+// resolve returns the values of the references, keyed by the name of their env var.
+func (r *ReferenceResolver) resolve(ctx context.Context, references map[string]string) (map[string]string, error) {
+	items := map[string]*model.Item{}
+	values := make(map[string]string, len(references))
+	for name, reference := range references {
+		itemPath, fieldName, err := parseSecretReference(reference)
+		if err != nil {
+			return nil, fmt.Errorf("env var %s: %w", name, err)
+		}
+		item, found := items[itemPath]
+		if !found {
+			if item, err = op.GetOnePasswordItemMetadataByPath(ctx, r.OpClient, itemPath); err != nil {
+				return nil, fmt.Errorf("env var %s: %w", name, err)
+			}
+			items[itemPath] = item
+		}
+		i := slices.IndexFunc(item.Fields, func(f model.ItemField) bool {
+			return f.Label == fieldName || f.ID == fieldName
+		})
+		if i < 0 {
+			return nil, fmt.Errorf("env var %s: field %q not found in %s", name, fieldName, reference)
+		}
+		values[name] = item.Fields[i].Value
+	}
+	return values, nil
+}
+
+// parseSecretReference splits op://<vault>/<item>/[<section>/]<field> into the operator's item path
+// and the field. The section is not needed to find the field, as items are matched by field label or ID.
+func parseSecretReference(reference string) (string, string, error) {
+	parts := strings.Split(strings.TrimPrefix(reference, injector.ReferencePrefix), "/")
+	if !strings.HasPrefix(reference, injector.ReferencePrefix) || len(parts) < 3 || len(parts) > 4 ||
+		slices.Contains(parts, "") {
+		return "", "", fmt.Errorf("invalid secret reference %q, expected op://<vault>/<item>/[<section>/]<field>",
+			reference)
+	}
+	return fmt.Sprintf("vaults/%s/items/%s", parts[0], parts[1]), parts[len(parts)-1], nil
+}
@@ -0,0 +1,199 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	"github.com/1Password/onepassword-operator/pkg/injector"
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const testPodToken = "pod-token"
+
+func newInjectedTestPod() *corev1.Pod {
+	pod := newTestPod(map[string]string{
+		op.InjectAnnotation:          "app",
+		op.InjectionStatusAnnotation: InjectionStatusInjected,
+	}, secretRefEnv, plainEnv)
+	pod.Namespace = testNamespace
+	pod.UID = "pod-uid"
+	return pod
+}
+
+func newTestReferenceResolver(t *testing.T, pod *corev1.Pod) *ReferenceResolver {
+	t.Helper()
+	cl := interceptor.NewClient(newFakeClient(t, pod).(client.WithWatch), interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			review, ok := obj.(*authenticationv1.TokenReview)
+			if !ok {
+				return c.Create(ctx, obj, opts...)
+			}
+			if review.Spec.Token == testPodToken {
+				review.Status = authenticationv1.TokenReviewStatus{
+					Authenticated: true,
+					Audiences:     review.Spec.Audiences,
+					User: authenticationv1.UserInfo{
+						Username: "system:serviceaccount:" + testNamespace + ":default",
+						Extra: map[string]authenticationv1.ExtraValue{
+							podNameExtra: {"app"},
+							podUIDExtra:  {"pod-uid"},
+						},
+					},
+				}
+			}
+			return nil
+		},
+	})
+
+	opClient := &mocks.TestClient{}
+	opClient.On("GetVaultsByTitle", "vault").Return([]model.Vault{{ID: "vault-id"}}, nil)
+	opClient.On("GetItemsByTitle", "vault-id", "item").Return([]model.Item{{ID: "item-id"}}, nil)
+	opClient.On("GetItemByID", "vault-id", "item-id").Return(&model.Item{
+		ID:      "item-id",
+		VaultID: "vault-id",
+		Fields: []model.ItemField{
+			{ID: "username", Label: "username", Value: "admin"},
+			{ID: "password", Label: "password", Value: "s3cr3t"},
+		},
+	}, nil)
+	return &ReferenceResolver{Client: cl, Reader: cl, OpClient: opClient}
+}
+
+func TestReferenceResolver(t *testing.T) {
+	testCases := map[string]struct {
+		pod            *corev1.Pod
+		token          string
+		request        injector.ResolveRequest
+		expectedStatus int
+		expectedValues map[string]string
+	}{
+		"references of an injected container are resolved": {
+			pod:   newInjectedTestPod(),
+			token: testPodToken,
+			request: injector.ResolveRequest{
+				Container:  "app",
+				References: map[string]string{"DB_PASSWORD": "op://vault/item/password"},
+			},
+			expectedStatus: http.StatusOK,
+			expectedValues: map[string]string{"DB_PASSWORD": "s3cr3t"},
+		},
+		"unauthenticated token is refused": {
+			pod:   newInjectedTestPod(),
+			token: "other-token",
+			request: injector.ResolveRequest{
+				Container:  "app",
+				References: map[string]string{"DB_PASSWORD": "op://vault/item/password"},
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"container not listed in the inject annotation is refused": {
+			pod:   newInjectedTestPod(),
+			token: testPodToken,
+			request: injector.ResolveRequest{
+				Container:  "sidecar",
+				References: map[string]string{"DB_PASSWORD": "op://vault/item/password"},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		"reference not in the container spec is refused": {
+			pod:   newInjectedTestPod(),
+			token: testPodToken,
+			request: injector.ResolveRequest{
+				Container:  "app",
+				References: map[string]string{"DB_PASSWORD": "op://vault/item/username"},
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		"pod replaced since the token was issued is refused": {
+			pod: func() *corev1.Pod {
+				pod := newInjectedTestPod()
+				pod.UID = "new-pod-uid"
+				return pod
+			}(),
+			token: testPodToken,
+			request: injector.ResolveRequest{
+				Container:  "app",
+				References: map[string]string{"DB_PASSWORD": "op://vault/item/password"},
+			},
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			resolver := newTestReferenceResolver(t, tc.pod)
+			body, err := json.Marshal(tc.request)
+			require.NoError(t, err)
+			req := httptest.NewRequest(http.MethodPost, injector.ResolvePath, bytes.NewReader(body))
+			req.Header.Set("Authorization", "Bearer "+tc.token)
+			rec := httptest.NewRecorder()
+
+			resolver.ServeHTTP(rec, req)
+
+			require.Equal(t, tc.expectedStatus, rec.Code, rec.Body.String())
+			if tc.expectedStatus != http.StatusOK {
+				return
+			}
+			var response injector.ResolveResponse
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&response))
+			require.Equal(t, tc.expectedValues, response.Values)
+		})
+	}
+}
+
+func TestParseSecretReference(t *testing.T) {
+	testCases := map[string]struct {
+		reference         string
+		expectedItemPath  string
+		expectedFieldName string
+		expectedError     bool
+	}{
+		"field": {
+			reference:         "op://vault/item/password",
+			expectedItemPath:  "vaults/vault/items/item",
+			expectedFieldName: "password",
+		},
+		"field in section": {
+			reference:         "op://vault/item/database/password",
+			expectedItemPath:  "vaults/vault/items/item",
+			expectedFieldName: "password",
+		},
+		"missing field": {
+			reference:     "op://vault/item",
+			expectedError: true,
+		},
+		"empty item": {
+			reference:     "op://vault//password",
+			expectedError: true,
+		},
+		"not a reference": {
+			reference:     "vault/item/password",
+			expectedError: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			itemPath, fieldName, err := parseSecretReference(tc.reference)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedItemPath, itemPath)
+			require.Equal(t, tc.expectedFieldName, fieldName)
+		})
+	}
+}
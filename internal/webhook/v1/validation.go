@@ -0,0 +1,140 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/utils"
+)
+
+// ValidatorConfig configures the validating webhooks.
+type ValidatorConfig struct {
+	// OpClient, when set, is used to check that the vault and item of an item path exist.
+	OpClient opclient.Client
+}
+
+func validateItemPath(path *field.Path, itemPath string) *field.Error {
+	if itemPath == "" {
+		return field.Required(path, "must be set to vaults/{vault_id_or_title}/items/{item_id_or_title}")
+	}
+	if _, _, err := op.ParseVaultAndItemFromPath(itemPath); err != nil {
+		return field.Invalid(path, itemPath, "must be of the format vaults/{vault_id_or_title}/items/{item_id_or_title}")
+	}
+	return nil
+}
+
+func validateBoolAnnotation(path *field.Path, annotations map[string]string, key string) *field.Error {
+	value, ok := annotations[key]
+	if !ok {
+		return nil
+	}
+	if _, err := utils.StringToBool(value); err != nil {
+		return field.Invalid(path.Key(key), value, "must be true or false")
+	}
+	return nil
+}
+
+// validateItemExists checks the item online when an OpClient is configured.
+func (c ValidatorConfig) validateItemExists(ctx context.Context, path *field.Path, itemPath string) *field.Error {
+	if c.OpClient == nil {
+		return nil
+	}
+	if err := op.CheckOnePasswordItemExists(ctx, c.OpClient, itemPath); err != nil {
+		return field.Invalid(path, itemPath, err.Error())
+	}
+	return nil
+}
+
+// secretOwner describes the resource a Secret is generated for.
+type secretOwner struct {
+	kind     string
+	name     string
+	uid      types.UID
+	itemPath string
+}
+
+func (o secretOwner) String() string {
+	return fmt.Sprintf("%s %q", o.kind, o.name)
+}
+
+// findSecretOwners returns the OnePasswordItems and annotated workloads in the namespace
+// that generate the Secret named secretName.
+func findSecretOwners(ctx context.Context, c client.Reader, namespace, secretName string) ([]secretOwner, error) {
+	var owners []secretOwner
+
+	items := &onepasswordv1.OnePasswordItemList{}
+	if err := c.List(ctx, items, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list OnePasswordItems: %w", err)
+	}
+	for _, item := range items.Items {
+		if kubeSecrets.FormatSecretName(item.Name) == secretName {
+			owners = append(owners, secretOwner{
+				kind:     "OnePasswordItem",
+				name:     item.Name,
+				uid:      item.UID,
+				itemPath: item.Spec.ItemPath,
+			})
+		}
+	}
+
+	for _, list := range op.NewWorkloadLists() {
+		if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list workloads: %w", err)
+		}
+		objs, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			workload, ok := obj.(client.Object)
+			if !ok {
+				continue
+			}
+			annotations := workloadItemAnnotations(workload)
+			name := annotations[op.NameAnnotation]
+			if name == "" || kubeSecrets.FormatSecretName(name) != secretName {
+				continue
+			}
+			owners = append(owners, secretOwner{
+				kind:     workloadKind(workload),
+				name:     workload.GetName(),
+				uid:      workload.GetUID(),
+				itemPath: annotations[op.ItemPathAnnotation],
+			})
+		}
+	}
+	return owners, nil
+}
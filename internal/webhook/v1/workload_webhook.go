@@ -0,0 +1,194 @@
+/*
+MIT License
+
+Copyright (c) 2020-2024 1Password
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in all
+copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+SOFTWARE.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+)
+
+// SetupWorkloadWebhooksWithManager registers a validating webhook for every workload kind
+// supported by the annotation-driven flow.
+func SetupWorkloadWebhooksWithManager(mgr ctrl.Manager, config ValidatorConfig) error {
+	for _, workload := range op.NewWorkloads() {
+		err := ctrl.NewWebhookManagedBy(mgr).For(workload).
+			WithValidator(&WorkloadCustomValidator{Client: mgr.GetClient(), Config: config}).
+			Complete()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-apps-v1-deployment,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=deployments,verbs=create;update,versions=v1,name=vdeployment-v1.onepassword.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-v1-statefulset,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=statefulsets,verbs=create;update,versions=v1,name=vstatefulset-v1.onepassword.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-apps-v1-daemonset,mutating=false,failurePolicy=ignore,sideEffects=None,groups=apps,resources=daemonsets,verbs=create;update,versions=v1,name=vdaemonset-v1.onepassword.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-batch-v1-job,mutating=false,failurePolicy=ignore,sideEffects=None,groups=batch,resources=jobs,verbs=create;update,versions=v1,name=vjob-v1.onepassword.com,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/validate-batch-v1-cronjob,mutating=false,failurePolicy=ignore,sideEffects=None,groups=batch,resources=cronjobs,verbs=create;update,versions=v1,name=vcronjob-v1.onepassword.com,admissionReviewVersions=v1
+
+// WorkloadCustomValidator rejects workloads with 1Password annotations the reconciler would fail on.
+// Workloads without 1Password annotations are always allowed.
+type WorkloadCustomValidator struct {
+	Client client.Reader
+	Config ValidatorConfig
+}
+
+var _ admission.CustomValidator = &WorkloadCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the workload kinds.
+func (v *WorkloadCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	workload, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("expected a workload object but got %T", obj)
+	}
+	return nil, v.validate(ctx, workload, nil)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the workload kinds.
+func (v *WorkloadCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldWorkload, ok := oldObj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("expected a workload object for the oldObj but got %T", oldObj)
+	}
+	workload, ok := newObj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("expected a workload object for the newObj but got %T", newObj)
+	}
+	return nil, v.validate(ctx, workload, oldWorkload)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the workload kinds.
+func (v *WorkloadCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func (v *WorkloadCustomValidator) validate(ctx context.Context, workload, oldWorkload client.Object) error {
+	if !workload.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	annotationsField := field.NewPath("metadata", "annotations")
+	if err := validateBoolAnnotation(annotationsField, workload.GetAnnotations(), op.AutoRestartWorkloadAnnotation); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	annotations, annotationsField := workloadItemAnnotationsWithPath(workload)
+	itemPath, hasItemPath := annotations[op.ItemPathAnnotation]
+	secretName, hasSecretName := annotations[op.NameAnnotation]
+	if hasItemPath || hasSecretName {
+		if err := validateItemPath(annotationsField.Key(op.ItemPathAnnotation), itemPath); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		if secretName == "" {
+			allErrs = append(allErrs, field.Required(annotationsField.Key(op.NameAnnotation),
+				"must be set to the name of the Secret to generate"))
+		}
+	}
+	if err := validateBoolAnnotation(annotationsField, annotations, kubeSecrets.CategoryPresetAnnotation); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if secretName != "" {
+		formattedName := kubeSecrets.FormatSecretName(secretName)
+		owners, err := findSecretOwners(ctx, v.Client, workload.GetNamespace(), formattedName)
+		if err != nil {
+			return err
+		}
+		kind := workloadKind(workload)
+		for _, owner := range owners {
+			if owner.uid == workload.GetUID() && owner.kind == kind {
+				continue
+			}
+			// Workloads may share a Secret as long as it is generated from the same item.
+			if owner.kind != "OnePasswordItem" && owner.itemPath == itemPath {
+				continue
+			}
+			allErrs = append(allErrs, field.Forbidden(annotationsField.Key(op.NameAnnotation), fmt.Sprintf(
+				"Secret %q is already generated for %s", formattedName, owner)))
+		}
+	}
+
+	itemPathChanged := oldWorkload == nil || workloadItemAnnotations(oldWorkload)[op.ItemPathAnnotation] != itemPath
+	if len(allErrs) == 0 && hasItemPath && itemPathChanged {
+		if err := v.Config.validateItemExists(ctx, annotationsField.Key(op.ItemPathAnnotation), itemPath); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	gvk, err := apiutil.GVKForObject(workload, clientgoscheme.Scheme)
+	if err != nil {
+		return err
+	}
+	return apierrors.NewInvalid(gvk.GroupKind(), workload.GetName(), allErrs)
+}
+
+// workloadItemAnnotations returns the 1Password annotations of a workload,
+// read from the workload itself or else from its pod template, like the reconciler does.
+func workloadItemAnnotations(workload client.Object) map[string]string {
+	annotations, _ := workloadItemAnnotationsWithPath(workload)
+	return annotations
+}
+
+func workloadItemAnnotationsWithPath(workload client.Object) (map[string]string, *field.Path) {
+	annotations, found := op.GetAnnotationsForWorkload(workload, op.AnnotationRegExp)
+	metadataPath := field.NewPath("metadata", "annotations")
+	if !found || len(op.FilterAnnotations(workload.GetAnnotations(), op.AnnotationRegExp)) > 0 {
+		return annotations, metadataPath
+	}
+	return annotations, podTemplatePath(workload).Child("metadata", "annotations")
+}
+
+func podTemplatePath(workload client.Object) *field.Path {
+	if _, ok := workload.(*batchv1.CronJob); ok {
+		return field.NewPath("spec", "jobTemplate", "spec", "template")
+	}
+	return field.NewPath("spec", "template")
+}
+
+func workloadKind(workload client.Object) string {
+	gvk, err := apiutil.GVKForObject(workload, clientgoscheme.Scheme)
+	if err != nil {
+		return fmt.Sprintf("%T", workload)
+	}
+	return gvk.Kind
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	op "github.com/1Password/onepassword-operator/pkg/onepassword"
+)
+
+func newAnnotatedDeployment(name string, annotations map[string]string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   testNamespace,
+			UID:         types.UID("uid-" + name),
+			Annotations: annotations,
+		},
+	}
+}
+
+func TestWorkloadValidateCreate(t *testing.T) {
+	validAnnotations := map[string]string{
+		op.ItemPathAnnotation: testItemPath,
+		op.NameAnnotation:     "secret",
+	}
+
+	testCases := map[string]struct {
+		workload      client.Object
+		existing      []client.Object
+		expectedError string
+	}{
+		"workload without annotations": {
+			workload: newAnnotatedDeployment("app", nil),
+		},
+		"valid annotations": {
+			workload: newAnnotatedDeployment("app", validAnnotations),
+		},
+		"malformed item path": {
+			workload: newAnnotatedDeployment("app", map[string]string{
+				op.ItemPathAnnotation: "vaults//items",
+				op.NameAnnotation:     "secret",
+			}),
+			expectedError: "metadata.annotations[operator.1password.io/item-path]: Invalid value: \"vaults//items\"",
+		},
+		"missing item name": {
+			workload: newAnnotatedDeployment("app", map[string]string{
+				op.ItemPathAnnotation: testItemPath,
+			}),
+			expectedError: "metadata.annotations[operator.1password.io/item-name]: Required value",
+		},
+		"non-bool auto-restart": {
+			workload: newAnnotatedDeployment("app", map[string]string{
+				op.AutoRestartWorkloadAnnotation: "sometimes",
+			}),
+			expectedError: "metadata.annotations[operator.1password.io/auto-restart]: Invalid value: \"sometimes\"",
+		},
+		"template annotations are reported at the template path": {
+			workload: func() client.Object {
+				cronJob := &batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: testNamespace}}
+				cronJob.Spec.JobTemplate.Spec.Template.Annotations = map[string]string{
+					op.ItemPathAnnotation: "not-a-path",
+					op.NameAnnotation:     "secret",
+				}
+				return cronJob
+			}(),
+			expectedError: "spec.jobTemplate.spec.template.metadata.annotations[operator.1password.io/item-path]",
+		},
+		"secret shared with a workload using the same item": {
+			workload: newAnnotatedDeployment("app", validAnnotations),
+			existing: []client.Object{newAnnotatedDeployment("other", validAnnotations)},
+		},
+		"secret generated from another item": {
+			workload: newAnnotatedDeployment("app", validAnnotations),
+			existing: []client.Object{newAnnotatedDeployment("other", map[string]string{
+				op.ItemPathAnnotation: "vaults/vault/items/other",
+				op.NameAnnotation:     "secret",
+			})},
+			expectedError: "metadata.annotations[operator.1password.io/item-name]: Forbidden: Secret \"secret\" is already generated for Deployment \"other\"",
+		},
+		"secret generated for a OnePasswordItem": {
+			workload:      newAnnotatedDeployment("app", validAnnotations),
+			existing:      []client.Object{newOnePasswordItem("secret", testItemPath)},
+			expectedError: "is already generated for OnePasswordItem \"secret\"",
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			validator := &WorkloadCustomValidator{Client: newFakeClient(t, tc.existing...)}
+			_, err := validator.ValidateCreate(context.Background(), tc.workload)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestWorkloadValidateUpdateIgnoresItself(t *testing.T) {
+	deployment := newAnnotatedDeployment("app", map[string]string{
+		op.ItemPathAnnotation: testItemPath,
+		op.NameAnnotation:     "secret",
+	})
+	validator := &WorkloadCustomValidator{Client: newFakeClient(t, deployment)}
+
+	updated := deployment.DeepCopy()
+	updated.Annotations[op.ItemPathAnnotation] = "vaults/vault/items/other"
+	_, err := validator.ValidateUpdate(context.Background(), deployment, updated)
+	require.NoError(t, err)
+}
@@ -0,0 +1,238 @@
+// Package injector resolves op:// references in the env of containers injected by the Pod webhook.
+//
+// The webhook copies the operator binary into the pod, where it runs as `op run -- <command>`. It sends
+// the references of its container to the operator, which resolves them with its 1Password client, and
+// replaces itself with the command, passing the resolved values in its env. The values never pass
+// through the Kubernetes API.
+package injector
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// InstallCommand copies the operator binary to the path given as its argument.
+	InstallCommand = "install-injector"
+	// RunCommand resolves the references and runs the command following `--`.
+	RunCommand = "run"
+	// BinaryName is the name the operator binary is installed under in pods.
+	BinaryName = "op"
+
+	// ResolvePath is the path of the operator endpoint resolving references.
+	ResolvePath = "/resolve"
+
+	// URLEnv is the env var holding the URL of the resolve endpoint.
+	URLEnv = "OP_INJECTOR_URL"
+	// CAEnv is the env var holding the PEM encoded CA of the resolve endpoint's certificate.
+	CAEnv = "OP_INJECTOR_CA"
+	// ContainerEnv is the env var holding the name of the container the references belong to.
+	ContainerEnv = "OP_INJECTOR_CONTAINER"
+
+	// TokenAudience is the audience of the service account token authenticating the pod to the operator.
+	TokenAudience = "onepassword-operator-injector"
+	// TokenMountPath is the directory the projected service account token is mounted in.
+	TokenMountPath = "/op/token"
+	// TokenFile is the name of the projected service account token file.
+	TokenFile = "token"
+
+	// ReferencePrefix is the prefix of env var values resolved from 1Password.
+	ReferencePrefix = "op://"
+
+	requestTimeout = 30 * time.Second
+)
+
+// ResolveRequest is the body of a request to the resolve endpoint.
+type ResolveRequest struct {
+	// Container is the name of the container the references belong to.
+	Container string `json:"container"`
+	// References maps env var names to their op:// reference.
+	References map[string]string `json:"references"`
+}
+
+// ResolveResponse is the body of a successful response of the resolve endpoint.
+type ResolveResponse struct {
+	// Values maps env var names to the value their reference resolved to.
+	Values map[string]string `json:"values"`
+}
+
+// IsCommand reports whether the binary was started as the injector rather than as the operator.
+func IsCommand(args []string) bool {
+	if len(args) < 2 {
+		return false
+	}
+	return args[1] == InstallCommand || (filepath.Base(args[0]) == BinaryName && args[1] == RunCommand)
+}
+
+// Main runs the injector command in args, as checked by IsCommand. On success, running a command
+// doesn't return as the process is replaced by the command.
+func Main(ctx context.Context, args []string) error {
+	if args[1] == InstallCommand {
+		if len(args) != 3 {
+			return fmt.Errorf("usage: %s %s <path>", args[0], InstallCommand)
+		}
+		return Install(args[2])
+	}
+
+	command := args[2:]
+	if len(command) > 0 && command[0] == "--" {
+		command = command[1:]
+	}
+	if len(command) == 0 {
+		return fmt.Errorf("usage: %s %s -- <command> [args...]", args[0], RunCommand)
+	}
+	return Run(ctx, command)
+}
+
+// Install copies the running binary to dest, so it can be run from a volume shared with other containers.
+func Install(dest string) error {
+	src, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the running binary: %w", err)
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dest, err)
+	}
+	return out.Close()
+}
+
+// Run resolves the references in the env through the operator and replaces the process with command.
+func Run(ctx context.Context, command []string) error {
+	env := os.Environ()
+	references := References(env)
+
+	var values map[string]string
+	if len(references) > 0 {
+		r := resolver{
+			url:       os.Getenv(URLEnv),
+			ca:        []byte(os.Getenv(CAEnv)),
+			tokenPath: filepath.Join(TokenMountPath, TokenFile),
+		}
+		var err error
+		if values, err = r.resolve(ctx, os.Getenv(ContainerEnv), references); err != nil {
+			return err
+		}
+	}
+
+	path, err := exec.LookPath(command[0])
+	if err != nil {
+		return err
+	}
+	return syscall.Exec(path, command, ResolvedEnv(env, values))
+}
+
+// References returns the env vars in env whose value is an op:// reference, keyed by name.
+func References(env []string) map[string]string {
+	references := map[string]string{}
+	for _, kv := range env {
+		name, value, _ := strings.Cut(kv, "=")
+		if strings.HasPrefix(value, ReferencePrefix) {
+			references[name] = value
+		}
+	}
+	return references
+}
+
+// ResolvedEnv returns env with the values of resolved references, and without the env vars
+// configuring the injector, which the command has no use for.
+func ResolvedEnv(env []string, values map[string]string) []string {
+	resolved := make([]string, 0, len(env))
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if slices.Contains([]string{URLEnv, CAEnv, ContainerEnv}, name) {
+			continue
+		}
+		if value, ok := values[name]; ok {
+			kv = name + "=" + value
+		}
+		resolved = append(resolved, kv)
+	}
+	return resolved
+}
+
+// resolver requests the values of references from the operator.
+type resolver struct {
+	url       string
+	ca        []byte
+	tokenPath string
+}
+
+func (r resolver) resolve(ctx context.Context, container string, references map[string]string) (map[string]string, error) {
+	if r.url == "" {
+		return nil, fmt.Errorf("%s is not set", URLEnv)
+	}
+	token, err := os.ReadFile(r.tokenPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the service account token: %w", err)
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if len(r.ca) > 0 {
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(r.ca) {
+			return nil, fmt.Errorf("%s holds no PEM encoded certificate", CAEnv)
+		}
+	}
+	httpClient := &http.Client{
+		Timeout:   requestTimeout,
+		Transport: &http.Transport{TLSClientConfig: tlsConfig, Proxy: http.ProxyFromEnvironment},
+	}
+
+	body, err := json.Marshal(ResolveRequest{Container: container, References: references})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve op:// references: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("failed to resolve op:// references: %s: %s",
+			resp.Status, strings.TrimSpace(string(message)))
+	}
+	var resolved ResolveResponse
+	if err := json.NewDecoder(resp.Body).Decode(&resolved); err != nil {
+		return nil, fmt.Errorf("failed to decode resolved op:// references: %w", err)
+	}
+	for name := range references {
+		if _, ok := resolved.Values[name]; !ok {
+			return nil, errors.New("the operator did not resolve the reference of " + name)
+		}
+	}
+	return resolved.Values, nil
+}
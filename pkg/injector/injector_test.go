@@ -0,0 +1,82 @@
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsCommand(t *testing.T) {
+	testCases := map[string]struct {
+		args     []string
+		expected bool
+	}{
+		"operator":         {args: []string{"/manager", "--leader-elect"}, expected: false},
+		"operator no args": {args: []string{"/manager"}, expected: false},
+		"install":          {args: []string{"/manager", InstallCommand, "/op/bin/op"}, expected: true},
+		"run":              {args: []string{"/op/bin/op", RunCommand, "--", "/app"}, expected: true},
+		"run as manager":   {args: []string{"/manager", RunCommand}, expected: false},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, IsCommand(tc.args))
+		})
+	}
+}
+
+func TestReferencesAndResolvedEnv(t *testing.T) {
+	env := []string{
+		"DB_PASSWORD=op://vault/item/password",
+		"DB_USER=app",
+		URLEnv + "=https://operator/resolve",
+		ContainerEnv + "=app",
+	}
+
+	references := References(env)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "op://vault/item/password"}, references)
+	assert.Equal(t, []string{"DB_PASSWORD=s3cr3t", "DB_USER=app"},
+		ResolvedEnv(env, map[string]string{"DB_PASSWORD": "s3cr3t"}))
+}
+
+func TestResolve(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pod-token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var request ResolveRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil || request.Container != "app" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(ResolveResponse{Values: map[string]string{"DB_PASSWORD": "s3cr3t"}})
+	}))
+	defer server.Close()
+
+	ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	tokenPath := filepath.Join(t.TempDir(), TokenFile)
+	require.NoError(t, os.WriteFile(tokenPath, []byte("pod-token\n"), 0o600))
+	references := map[string]string{"DB_PASSWORD": "op://vault/item/password"}
+
+	r := resolver{url: server.URL + ResolvePath, ca: ca, tokenPath: tokenPath}
+	values, err := r.resolve(context.Background(), "app", references)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"DB_PASSWORD": "s3cr3t"}, values)
+
+	_, err = r.resolve(context.Background(), "sidecar", references)
+	assert.ErrorContains(t, err, "400 Bad Request: bad request")
+
+	// The server certificate is not trusted without the CA.
+	r.ca = nil
+	_, err = r.resolve(context.Background(), "app", references)
+	assert.ErrorContains(t, err, "certificate")
+}
@@ -0,0 +1,69 @@
+package kubernetessecrets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ContentHashAnnotation records a hash of the data the operator last wrote to a secret,
+// so that changes made by anyone else can be detected and reverted.
+const ContentHashAnnotation = OnepasswordPrefix + "/content-hash"
+
+// FieldManager is the field manager the operator writes secrets with. Any other manager
+// found in a secret's managedFields changed it outside of the operator.
+const FieldManager = "onepassword-operator"
+
+// ContentHash returns a stable hash of the secret data.
+func ContentHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for key := range data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	for _, key := range keys {
+		hash.Write([]byte(key))
+		hash.Write([]byte{0})
+		hash.Write(data[key])
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// IsDrifted reports whether the secret data no longer matches what the operator last wrote.
+// Secrets written before the content hash was recorded are never reported as drifted.
+func IsDrifted(secret *corev1.Secret) bool {
+	hash, ok := secret.Annotations[ContentHashAnnotation]
+	return ok && hash != ContentHash(secret.Data)
+}
+
+// LastChangedBy returns the most recent field manager other than the operator that
+// changed the secret, preferring managers that own its data. It returns "unknown"
+// when managedFields do not tell.
+func LastChangedBy(secret *corev1.Secret) string {
+	changedBy := ""
+	changedData := false
+	var changedAt int64
+	for _, entry := range secret.ManagedFields {
+		if entry.Manager == FieldManager {
+			continue
+		}
+		ownsData := entry.FieldsV1 != nil && strings.Contains(string(entry.FieldsV1.Raw), `"f:data"`)
+		var at int64
+		if entry.Time != nil {
+			at = entry.Time.Unix()
+		}
+		if changedBy == "" || (ownsData && !changedData) || (ownsData == changedData && at >= changedAt) {
+			changedBy, changedData, changedAt = entry.Manager, ownsData, at
+		}
+	}
+	if changedBy == "" {
+		return "unknown"
+	}
+	return changedBy
+}
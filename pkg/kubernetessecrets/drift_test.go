@@ -0,0 +1,133 @@
+package kubernetessecrets
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+func TestContentHash(t *testing.T) {
+	data := map[string][]byte{"username": []byte("admin"), "password": []byte("secret")}
+	require.Equal(t, ContentHash(data), ContentHash(map[string][]byte{"password": []byte("secret"), "username": []byte("admin")}))
+	require.NotEqual(t, ContentHash(data), ContentHash(map[string][]byte{"username": []byte("admin")}))
+	// Keys and values must not be interchangeable.
+	require.NotEqual(t,
+		ContentHash(map[string][]byte{"ab": []byte("c")}),
+		ContentHash(map[string][]byte{"a": []byte("bc")}),
+	)
+}
+
+func TestIsDrifted(t *testing.T) {
+	data := map[string][]byte{"password": []byte("secret")}
+
+	testCases := map[string]struct {
+		secret   *corev1.Secret
+		expected bool
+	}{
+		"unchanged": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ContentHashAnnotation: ContentHash(data)}},
+				Data:       data,
+			},
+		},
+		"changed": {
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{ContentHashAnnotation: ContentHash(data)}},
+				Data:       map[string][]byte{"password": []byte("changed")},
+			},
+			expected: true,
+		},
+		"no content hash": {
+			secret: &corev1.Secret{Data: data},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			require.Equal(t, tc.expected, IsDrifted(tc.secret))
+		})
+	}
+}
+
+func TestLastChangedBy(t *testing.T) {
+	now := time.Now()
+	dataFields := &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:password":{}}}`)}
+	labelFields := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:labels":{}}}`)}
+
+	testCases := map[string]struct {
+		managedFields []metav1.ManagedFieldsEntry
+		expected      string
+	}{
+		"no managed fields": {
+			expected: "unknown",
+		},
+		"only the operator": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: FieldManager, Time: &metav1.Time{Time: now}, FieldsV1: dataFields},
+			},
+			expected: "unknown",
+		},
+		"most recent data manager": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: FieldManager, Time: &metav1.Time{Time: now}, FieldsV1: dataFields},
+				{Manager: "kubectl-edit", Time: &metav1.Time{Time: now.Add(-time.Hour)}, FieldsV1: dataFields},
+				{Manager: "kubectl-patch", Time: &metav1.Time{Time: now.Add(-time.Minute)}, FieldsV1: dataFields},
+				{Manager: "kubectl-label", Time: &metav1.Time{Time: now}, FieldsV1: labelFields},
+			},
+			expected: "kubectl-patch",
+		},
+		"falls back to other managers": {
+			managedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "kubectl-label", Time: &metav1.Time{Time: now}, FieldsV1: labelFields},
+			},
+			expected: "kubectl-label",
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{ManagedFields: tc.managedFields}}
+			require.Equal(t, tc.expected, LastChangedBy(secret))
+		})
+	}
+}
+
+func TestCreateKubernetesSecretFromItemRestoresDriftedData(t *testing.T) {
+	ctx := context.Background()
+	secretName := "test-secret-name"
+
+	item := model.Item{}
+	item.Fields = generateFields(3)
+	item.Version = 123
+	item.VaultID = testVaultUUID
+	item.ID = testItemUUID
+
+	kubeClient := fake.NewClientBuilder().Build()
+	_, err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, testNamespace, &item,
+		SecretOptions{AutoRestart: restartDeploymentAnnotation})
+	require.NoError(t, err)
+
+	secret := &corev1.Secret{}
+	require.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: testNamespace}, secret))
+	require.Equal(t, ContentHash(secret.Data), secret.Annotations[ContentHashAnnotation])
+
+	secret.Data["key0"] = []byte("changed")
+	require.NoError(t, kubeClient.Update(ctx, secret))
+
+	_, err = CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, testNamespace, &item,
+		SecretOptions{AutoRestart: restartDeploymentAnnotation})
+	require.NoError(t, err)
+
+	restored := &corev1.Secret{}
+	require.NoError(t, kubeClient.Get(ctx, types.NamespacedName{Name: secretName, Namespace: testNamespace}, restored))
+	require.False(t, IsDrifted(restored))
+	compareFields(item.Fields, restored.Data, t)
+}
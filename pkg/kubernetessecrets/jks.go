@@ -0,0 +1,176 @@
+package kubernetessecrets
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by the JKS format
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	jksMagic             = 0xFEEDFEED
+	jksVersion           = 2
+	jksPrivateKeyTag     = 1
+	jksTrustedCertTag    = 2
+	jksCertificateType   = "X.509"
+	jksIntegrityWhitener = "Mighty Aphrodite"
+	jksSaltLength        = sha1.Size
+)
+
+// oidJKSKeyProtector identifies Sun's proprietary JKS private key protection algorithm.
+var oidJKSKeyProtector = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 42, 2, 17, 1, 1}
+
+type jksTrustedCert struct {
+	alias string
+	cert  *x509.Certificate
+}
+
+// encodeJKSKeystore writes a JKS keystore holding a single private key entry and its certificate chain.
+// Randomness for the key protection salt is taken from rand so the output can be reproduced.
+func encodeJKSKeystore(rand io.Reader, alias string, pkcs8Key []byte, chain []*x509.Certificate,
+	created time.Time, password string) ([]byte, error) {
+	protectedKey, err := protectJKSKey(rand, pkcs8Key, password)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &jksWriter{}
+	w.writeHeader(1)
+	w.writeUint32(jksPrivateKeyTag)
+	w.writeUTF(alias)
+	w.writeTime(created)
+	w.writeBytes(protectedKey)
+	w.writeUint32(uint32(len(chain)))
+	for _, cert := range chain {
+		w.writeCertificate(cert)
+	}
+	return w.finish(password)
+}
+
+// encodeJKSTruststore writes a JKS keystore holding trusted certificate entries only.
+func encodeJKSTruststore(certs []jksTrustedCert, created time.Time, password string) ([]byte, error) {
+	w := &jksWriter{}
+	w.writeHeader(len(certs))
+	for _, c := range certs {
+		w.writeUint32(jksTrustedCertTag)
+		w.writeUTF(c.alias)
+		w.writeTime(created)
+		w.writeCertificate(c.cert)
+	}
+	return w.finish(password)
+}
+
+// protectJKSKey encrypts a PKCS#8 key the way sun.security.provider.KeyProtector does
+// and wraps it into an EncryptedPrivateKeyInfo structure.
+func protectJKSKey(rand io.Reader, pkcs8Key []byte, password string) ([]byte, error) {
+	salt := make([]byte, jksSaltLength)
+	if _, err := io.ReadFull(rand, salt); err != nil {
+		return nil, fmt.Errorf("failed to generate JKS key salt: %w", err)
+	}
+	passwordBytes := jksPasswordBytes(password)
+
+	keystream := make([]byte, 0, len(pkcs8Key)+sha1.Size)
+	digest := salt
+	for len(keystream) < len(pkcs8Key) {
+		h := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+		h.Write(passwordBytes)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		keystream = append(keystream, digest...)
+	}
+
+	encrypted := make([]byte, len(pkcs8Key))
+	for i := range pkcs8Key {
+		encrypted[i] = pkcs8Key[i] ^ keystream[i]
+	}
+
+	check := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+	check.Write(passwordBytes)
+	check.Write(pkcs8Key)
+
+	protected := make([]byte, 0, len(salt)+len(encrypted)+sha1.Size)
+	protected = append(protected, salt...)
+	protected = append(protected, encrypted...)
+	protected = check.Sum(protected)
+
+	return asn1.Marshal(struct {
+		Algorithm     pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}{
+		Algorithm: pkix.AlgorithmIdentifier{
+			Algorithm:  oidJKSKeyProtector,
+			Parameters: asn1.NullRawValue,
+		},
+		EncryptedData: protected,
+	})
+}
+
+// jksPasswordBytes returns the password as big-endian UTF-16, as Java hashes char arrays.
+func jksPasswordBytes(password string) []byte {
+	units := utf16.Encode([]rune(password))
+	b := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		b = append(b, byte(u>>8), byte(u))
+	}
+	return b
+}
+
+type jksWriter struct {
+	buf bytes.Buffer
+	err error
+}
+
+func (w *jksWriter) writeHeader(entries int) {
+	w.writeUint32(jksMagic)
+	w.writeUint32(jksVersion)
+	w.writeUint32(uint32(entries))
+}
+
+func (w *jksWriter) writeUint32(v uint32) {
+	_ = binary.Write(&w.buf, binary.BigEndian, v)
+}
+
+func (w *jksWriter) writeTime(t time.Time) {
+	_ = binary.Write(&w.buf, binary.BigEndian, uint64(t.UnixMilli()))
+}
+
+func (w *jksWriter) writeBytes(b []byte) {
+	w.writeUint32(uint32(len(b)))
+	w.buf.Write(b)
+}
+
+// writeUTF writes a string in Java's DataOutput.writeUTF encoding. Aliases are plain ASCII
+// in practice, so characters outside of it are rejected instead of emitting modified UTF-8.
+func (w *jksWriter) writeUTF(s string) {
+	for _, r := range s {
+		if r == 0 || r > 0x7f {
+			w.err = fmt.Errorf("JKS alias %q must only contain ASCII characters", s)
+			return
+		}
+	}
+	_ = binary.Write(&w.buf, binary.BigEndian, uint16(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *jksWriter) writeCertificate(cert *x509.Certificate) {
+	w.writeUTF(jksCertificateType)
+	w.writeBytes(cert.Raw)
+}
+
+// finish appends the keyed integrity digest that keytool verifies when the keystore is loaded.
+func (w *jksWriter) finish(password string) ([]byte, error) {
+	if w.err != nil {
+		return nil, w.err
+	}
+	h := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+	h.Write(jksPasswordBytes(password))
+	h.Write([]byte(jksIntegrityWhitener))
+	h.Write(w.buf.Bytes())
+	return h.Sum(w.buf.Bytes()), nil
+}
@@ -0,0 +1,296 @@
+package kubernetessecrets
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"software.sslmate.com/src/go-pkcs12"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const KeystoreChecksumAnnotation = OnepasswordPrefix + "/keystore-checksum"
+
+const (
+	PKCS12KeystoreKey   = "keystore.p12"
+	PKCS12TruststoreKey = "truststore.p12"
+	JKSKeystoreKey      = "keystore.jks"
+	JKSTruststoreKey    = "truststore.jks"
+	KeystorePasswordKey = "keystore-password"
+
+	defaultCertificateField    = "tls.crt"
+	defaultPrivateKeyField     = "tls.key"
+	defaultCACertificatesField = "ca.crt"
+	defaultKeystoreAlias       = "default"
+)
+
+// Keystore describes the Java keystore bundles to add to a secret. Items are searched,
+// in order, after the main item when looking up PEM content.
+type Keystore struct {
+	Spec  *onepasswordv1.OnePasswordItemKeystore
+	Items []model.Item
+}
+
+// Checksum identifies the keystore configuration and the versions of the items it reads from,
+// so that a change to either regenerates the bundles even if the main item is unchanged.
+func (k *Keystore) Checksum() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%v|%v|%v|%v|%v|%v", k.Spec.Formats, k.Spec.CertificateField, k.Spec.PrivateKeyField,
+		k.Spec.CACertificatesField, k.Spec.PasswordField, k.Spec.Alias)
+	for _, item := range k.Items {
+		fmt.Fprintf(h, "|vaults/%v/items/%v@%v", item.VaultID, item.ID, item.Version)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// AddKeystoreData generates the keystore bundles into data and records the keystore checksum in annotations.
+// When keystore is nil, the checksum annotation is removed.
+func AddKeystoreData(data map[string][]byte, annotations map[string]string, item model.Item, keystore *Keystore) error {
+	if keystore == nil || keystore.Spec == nil {
+		delete(annotations, KeystoreChecksumAnnotation)
+		return nil
+	}
+
+	keystoreData, err := BuildKeystoreData(keystore.Spec, append([]model.Item{item}, keystore.Items...))
+	if err != nil {
+		return err
+	}
+	for key, value := range keystoreData {
+		if _, exists := data[key]; exists {
+			log.Info(fmt.Sprintf("Field '%s' overwritten by the generated keystore bundle", key))
+		}
+		data[key] = value
+	}
+	annotations[KeystoreChecksumAnnotation] = keystore.Checksum()
+	return nil
+}
+
+// BuildKeystoreData assembles PKCS#12 and/or JKS keystore and truststore bundles from PEM
+// fields or files of the given items.
+//
+// The bundles are encoded with randomness derived from their inputs, so regenerating them
+// from unchanged items yields byte-identical data.
+func BuildKeystoreData(spec *onepasswordv1.OnePasswordItemKeystore, items []model.Item) (map[string][]byte, error) {
+	certificateField := valueOrDefault(spec.CertificateField, defaultCertificateField)
+	privateKeyField := valueOrDefault(spec.PrivateKeyField, defaultPrivateKeyField)
+	caCertificatesField := valueOrDefault(spec.CACertificatesField, defaultCACertificatesField)
+	alias := valueOrDefault(spec.Alias, defaultKeystoreAlias)
+
+	certPEM := findItemContent(items, certificateField)
+	if certPEM == nil {
+		return nil, fmt.Errorf("keystore certificate %q not found in item fields or files", certificateField)
+	}
+	chain, err := parseCertificates(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keystore certificate %q: %w", certificateField, err)
+	}
+
+	keyPEM := findItemContent(items, privateKeyField)
+	if keyPEM == nil {
+		return nil, fmt.Errorf("keystore private key %q not found in item fields or files", privateKeyField)
+	}
+	key, err := parsePrivateKey(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse keystore private key %q: %w", privateKeyField, err)
+	}
+	if !publicKeyMatches(key, chain[0]) {
+		return nil, fmt.Errorf("keystore private key %q does not match certificate %q", privateKeyField, certificateField)
+	}
+	pkcs8Key, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode keystore private key: %w", err)
+	}
+
+	var caCerts []*x509.Certificate
+	for _, content := range findAllItemContent(items, caCertificatesField) {
+		certs, err := parseCertificates(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse keystore CA certificates %q: %w", caCertificatesField, err)
+		}
+		caCerts = append(caCerts, certs...)
+	}
+
+	data := map[string][]byte{}
+	var password string
+	if spec.PasswordField != "" {
+		passwordValue := findItemContent(items, spec.PasswordField)
+		if len(passwordValue) == 0 {
+			return nil, fmt.Errorf("keystore password %q not found in item fields or files", spec.PasswordField)
+		}
+		password = string(passwordValue)
+	} else {
+		password = generateKeystorePassword(pkcs8Key)
+		data[KeystorePasswordKey] = []byte(password)
+	}
+
+	rand := newDeterministicReader(password, pkcs8Key, chain, caCerts)
+	created := chain[0].NotBefore
+
+	for _, format := range spec.Formats {
+		switch format {
+		case onepasswordv1.KeystoreFormatPKCS12:
+			keystore, err := pkcs12.Modern.WithRand(rand).Encode(key, chain[0], chain[1:], password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode PKCS#12 keystore: %w", err)
+			}
+			data[PKCS12KeystoreKey] = keystore
+
+			if len(caCerts) > 0 {
+				entries := make([]pkcs12.TrustStoreEntry, len(caCerts))
+				for i, cert := range caCerts {
+					entries[i] = pkcs12.TrustStoreEntry{Cert: cert, FriendlyName: caAlias(i)}
+				}
+				truststore, err := pkcs12.Modern.WithRand(rand).EncodeTrustStoreEntries(entries, password)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode PKCS#12 truststore: %w", err)
+				}
+				data[PKCS12TruststoreKey] = truststore
+			}
+		case onepasswordv1.KeystoreFormatJKS:
+			keystore, err := encodeJKSKeystore(rand, alias, pkcs8Key, chain, created, password)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode JKS keystore: %w", err)
+			}
+			data[JKSKeystoreKey] = keystore
+
+			if len(caCerts) > 0 {
+				entries := make([]jksTrustedCert, len(caCerts))
+				for i, cert := range caCerts {
+					entries[i] = jksTrustedCert{alias: caAlias(i), cert: cert}
+				}
+				truststore, err := encodeJKSTruststore(entries, created, password)
+				if err != nil {
+					return nil, fmt.Errorf("failed to encode JKS truststore: %w", err)
+				}
+				data[JKSTruststoreKey] = truststore
+			}
+		default:
+			return nil, fmt.Errorf("unsupported keystore format %q", format)
+		}
+	}
+
+	return data, nil
+}
+
+// findItemContent returns the first field value or file content matching name across items.
+func findItemContent(items []model.Item, name string) []byte {
+	content := findAllItemContent(items, name)
+	if len(content) == 0 {
+		return nil
+	}
+	return content[0]
+}
+
+func findAllItemContent(items []model.Item, name string) [][]byte {
+	var content [][]byte
+	for _, item := range items {
+		for _, field := range item.Fields {
+			if field.Label == name && field.Value != "" {
+				content = append(content, []byte(field.Value))
+			}
+		}
+		for _, file := range item.Files {
+			if file.Name != name {
+				continue
+			}
+			fileContent, err := file.Content()
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Could not load contents of file %s", file.Name))
+				continue
+			}
+			content = append(content, fileContent)
+		}
+	}
+	return content
+}
+
+func parseCertificates(data []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM certificate found")
+	}
+	return certs, nil
+}
+
+func parsePrivateKey(data []byte) (crypto.Signer, error) {
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		var key any
+		var err error
+		switch block.Type {
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "RSA PRIVATE KEY":
+			key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		case "EC PRIVATE KEY":
+			key, err = x509.ParseECPrivateKey(block.Bytes)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	}
+	return nil, errors.New("no PEM private key found")
+}
+
+func publicKeyMatches(key crypto.Signer, cert *x509.Certificate) bool {
+	publicKey, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	return ok && publicKey.Equal(cert.PublicKey)
+}
+
+// generateKeystorePassword derives a password from the private key so it stays stable
+// for as long as the key does, without having to be stored anywhere else.
+func generateKeystorePassword(pkcs8Key []byte) string {
+	mac := hmac.New(sha256.New, pkcs8Key)
+	mac.Write([]byte("onepassword-operator keystore password"))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// newDeterministicReader returns a SHAKE256 stream seeded with the bundle inputs. It replaces
+// crypto/rand for salts and IVs so that unchanged inputs produce unchanged bundles.
+func newDeterministicReader(password string, pkcs8Key []byte, chain, caCerts []*x509.Certificate) io.Reader {
+	shake := sha3.NewSHAKE256()
+	_, _ = shake.Write([]byte(password))
+	_, _ = shake.Write(pkcs8Key)
+	for _, cert := range slices.Concat(chain, caCerts) {
+		_, _ = shake.Write(cert.Raw)
+	}
+	return shake
+}
+
+func caAlias(i int) string {
+	return fmt.Sprintf("ca-%d", i)
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
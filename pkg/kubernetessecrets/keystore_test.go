@@ -0,0 +1,228 @@
+package kubernetessecrets
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // SHA-1 is mandated by the JKS format
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+type testCertificate struct {
+	cert    *x509.Certificate
+	certPEM string
+	keyPEM  string
+}
+
+func generateTestCertificate(t *testing.T, commonName string, parent *testCertificate) testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  parent == nil,
+		BasicConstraintsValid: true,
+	}
+	signerCert, signerKey := template, any(key)
+	if parent != nil {
+		parentKey, err := parsePrivateKey([]byte(parent.keyPEM))
+		require.NoError(t, err)
+		signerCert, signerKey = parent.cert, parentKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signerCert, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	return testCertificate{
+		cert:    cert,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestBuildKeystoreData(t *testing.T) {
+	ca := generateTestCertificate(t, "test-ca", nil)
+	leaf := generateTestCertificate(t, "test-leaf", &ca)
+
+	certItem := model.Item{
+		Fields: []model.ItemField{
+			{Label: "tls.crt", Value: leaf.certPEM},
+			{Label: "tls.key", Value: leaf.keyPEM},
+			{Label: "storepass", Value: "changeit"},
+		},
+	}
+	caFile := model.File{Name: "ca.crt"}
+	caFile.SetContent([]byte(ca.certPEM))
+	caItem := model.Item{Files: []model.File{caFile}}
+
+	t.Run("PKCS12 with CA from another item", func(t *testing.T) {
+		spec := &onepasswordv1.OnePasswordItemKeystore{
+			Formats:       []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatPKCS12},
+			PasswordField: "storepass",
+		}
+		data, err := BuildKeystoreData(spec, []model.Item{certItem, caItem})
+		require.NoError(t, err)
+		require.NotContains(t, data, KeystorePasswordKey)
+
+		_, cert, _, err := pkcs12.DecodeChain(data[PKCS12KeystoreKey], "changeit")
+		require.NoError(t, err)
+		require.Equal(t, leaf.cert.Raw, cert.Raw)
+
+		trusted, err := pkcs12.DecodeTrustStore(data[PKCS12TruststoreKey], "changeit")
+		require.NoError(t, err)
+		require.Len(t, trusted, 1)
+		require.Equal(t, ca.cert.Raw, trusted[0].Raw)
+	})
+
+	t.Run("JKS with generated password", func(t *testing.T) {
+		spec := &onepasswordv1.OnePasswordItemKeystore{
+			Formats: []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatJKS},
+		}
+		data, err := BuildKeystoreData(spec, []model.Item{certItem, caItem})
+		require.NoError(t, err)
+
+		password := string(data[KeystorePasswordKey])
+		require.NotEmpty(t, password)
+		verifyJKS(t, data[JKSKeystoreKey], password, 1)
+		verifyJKS(t, data[JKSTruststoreKey], password, 1)
+		require.NotContains(t, data, PKCS12KeystoreKey)
+	})
+
+	t.Run("regeneration is deterministic", func(t *testing.T) {
+		spec := &onepasswordv1.OnePasswordItemKeystore{
+			Formats: []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatPKCS12, onepasswordv1.KeystoreFormatJKS},
+		}
+		first, err := BuildKeystoreData(spec, []model.Item{certItem, caItem})
+		require.NoError(t, err)
+		second, err := BuildKeystoreData(spec, []model.Item{certItem, caItem})
+		require.NoError(t, err)
+		require.Equal(t, first, second)
+	})
+
+	t.Run("missing private key", func(t *testing.T) {
+		spec := &onepasswordv1.OnePasswordItemKeystore{
+			Formats:         []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatPKCS12},
+			PrivateKeyField: "missing",
+		}
+		_, err := BuildKeystoreData(spec, []model.Item{certItem})
+		require.ErrorContains(t, err, `keystore private key "missing" not found`)
+	})
+
+	t.Run("private key does not match certificate", func(t *testing.T) {
+		spec := &onepasswordv1.OnePasswordItemKeystore{
+			Formats:         []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatPKCS12},
+			PrivateKeyField: "other.key",
+		}
+		other := generateTestCertificate(t, "other", nil)
+		item := certItem
+		item.Fields = append([]model.ItemField{{Label: "other.key", Value: other.keyPEM}}, certItem.Fields...)
+		_, err := BuildKeystoreData(spec, []model.Item{item})
+		require.ErrorContains(t, err, "does not match certificate")
+	})
+}
+
+func TestAddKeystoreData(t *testing.T) {
+	ca := generateTestCertificate(t, "test-ca", nil)
+	leaf := generateTestCertificate(t, "test-leaf", &ca)
+	item := model.Item{
+		Fields: []model.ItemField{
+			{Label: "tls.crt", Value: leaf.certPEM},
+			{Label: "tls.key", Value: leaf.keyPEM},
+		},
+	}
+	keystore := &Keystore{
+		Spec: &onepasswordv1.OnePasswordItemKeystore{
+			Formats: []onepasswordv1.KeystoreFormat{onepasswordv1.KeystoreFormatPKCS12},
+		},
+		Items: []model.Item{{ID: "ca-item", VaultID: "vault", Version: 1}},
+	}
+
+	data := BuildKubernetesSecretData(item.Fields, nil, nil, false)
+	annotations := map[string]string{}
+	require.NoError(t, AddKeystoreData(data, annotations, item, keystore))
+	require.Contains(t, data, PKCS12KeystoreKey)
+	require.Contains(t, data, "tls.crt")
+	require.Equal(t, keystore.Checksum(), annotations[KeystoreChecksumAnnotation])
+
+	keystore.Items[0].Version = 2
+	require.NotEqual(t, annotations[KeystoreChecksumAnnotation], keystore.Checksum())
+
+	require.NoError(t, AddKeystoreData(data, annotations, item, nil))
+	require.NotContains(t, annotations, KeystoreChecksumAnnotation)
+}
+
+func TestProtectJKSKey(t *testing.T) {
+	plainKey := bytes.Repeat([]byte("pkcs8"), 13)
+	protected, err := protectJKSKey(rand.Reader, plainKey, "changeit")
+	require.NoError(t, err)
+
+	var info struct {
+		Algorithm     pkix.AlgorithmIdentifier
+		EncryptedData []byte
+	}
+	_, err = asn1.Unmarshal(protected, &info)
+	require.NoError(t, err)
+	require.True(t, info.Algorithm.Algorithm.Equal(oidJKSKeyProtector))
+
+	// Reverse sun.security.provider.KeyProtector.recover.
+	data := info.EncryptedData
+	salt, encrypted, check := data[:jksSaltLength], data[jksSaltLength:len(data)-sha1.Size], data[len(data)-sha1.Size:]
+	passwordBytes := jksPasswordBytes("changeit")
+	recovered := make([]byte, 0, len(encrypted))
+	digest := salt
+	for len(recovered) < len(encrypted) {
+		h := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+		h.Write(passwordBytes)
+		h.Write(digest)
+		digest = h.Sum(nil)
+		for i := 0; i < len(digest) && len(recovered) < len(encrypted); i++ {
+			recovered = append(recovered, encrypted[len(recovered)]^digest[i])
+		}
+	}
+	require.Equal(t, plainKey, recovered)
+
+	h := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+	h.Write(passwordBytes)
+	h.Write(recovered)
+	require.Equal(t, check, h.Sum(nil))
+}
+
+// verifyJKS checks the JKS header, entry count and integrity digest the way keytool does on load.
+func verifyJKS(t *testing.T, keystore []byte, password string, entries uint32) {
+	t.Helper()
+	require.Greater(t, len(keystore), 12+sha1.Size)
+
+	require.Equal(t, uint32(jksMagic), binary.BigEndian.Uint32(keystore[0:4]))
+	require.Equal(t, uint32(jksVersion), binary.BigEndian.Uint32(keystore[4:8]))
+	require.Equal(t, entries, binary.BigEndian.Uint32(keystore[8:12]))
+
+	body, digest := keystore[:len(keystore)-sha1.Size], keystore[len(keystore)-sha1.Size:]
+	h := sha1.New() //nolint:gosec // SHA-1 is mandated by the JKS format
+	h.Write(jksPasswordBytes(password))
+	h.Write([]byte(jksIntegrityWhitener))
+	h.Write(body)
+	require.True(t, bytes.Equal(digest, h.Sum(nil)), "JKS integrity digest mismatch")
+}
@@ -9,7 +9,9 @@ import (
 	"strings"
 
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
 	"github.com/1Password/onepassword-operator/pkg/utils"
+	"go.opentelemetry.io/otel/attribute"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,18 +32,37 @@ var ErrCannotUpdateSecretType = errors.New("cannot change secret type: secret ty
 
 var log = logf.Log
 
+// SecretOptions are the settings of the secret created for an item besides its name and data.
+type SecretOptions struct {
+	// AutoRestart is the value of the auto-restart annotation of the secret, not set when empty.
+	AutoRestart string
+	Labels      map[string]string
+	Annotations map[string]string
+	// Type is the type of the secret, Opaque when empty.
+	Type     string
+	OwnerRef *metav1.OwnerReference
+	// AllowEmptyValues keeps item fields without a value as empty keys.
+	AllowEmptyValues bool
+	// Keystore generates a keystore from the PEM content of the item when set.
+	Keystore *Keystore
+	// CategoryPreset is the value of the category-preset annotation of the secret, not set when empty.
+	CategoryPreset string
+}
+
+// CreateKubernetesSecretFromItem creates or updates the secret for an item. It returns the secret
+// when it was created or updated, and nil when the secret was already up to date.
 func CreateKubernetesSecretFromItem(
 	ctx context.Context,
 	kubeClient kubernetesClient.Client,
 	secretName, namespace string,
 	item *model.Item,
-	autoRestart string,
-	labels map[string]string,
-	secretAnnotations map[string]string,
-	secretType string,
-	ownerRef *metav1.OwnerReference,
-	allowEmptyValues bool,
-) error {
+	opts SecretOptions,
+) (_ *corev1.Secret, err error) {
+	ctx, span := tracing.Start(ctx, "CreateKubernetesSecretFromItem",
+		attribute.String("k8s.namespace.name", namespace), attribute.String("k8s.secret.name", secretName))
+	defer func() { tracing.End(span, err) }()
+
+	secretAnnotations := opts.Annotations
 	itemVersion := fmt.Sprint(item.Version)
 	if secretAnnotations == nil {
 		secretAnnotations = map[string]string{}
@@ -49,32 +70,49 @@ func CreateKubernetesSecretFromItem(
 	secretAnnotations[VersionAnnotation] = itemVersion
 	secretAnnotations[ItemPathAnnotation] = fmt.Sprintf("vaults/%v/items/%v", item.VaultID, item.ID)
 
-	if autoRestart != "" {
-		_, err := utils.StringToBool(autoRestart)
+	if opts.AutoRestart != "" {
+		_, err := utils.StringToBool(opts.AutoRestart)
 		if err != nil {
-			return fmt.Errorf("error parsing %v annotation on Secret %v. Must be true or false. Defaulting to false",
+			return nil, fmt.Errorf("error parsing %v annotation on Secret %v. Must be true or false. Defaulting to false",
 				RestartDeploymentsAnnotation, secretName,
 			)
 		}
-		secretAnnotations[RestartDeploymentsAnnotation] = autoRestart
+		secretAnnotations[RestartDeploymentsAnnotation] = opts.AutoRestart
+	}
+
+	if opts.CategoryPreset != "" {
+		_, err := utils.StringToBool(opts.CategoryPreset)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %v annotation on Secret %v. Must be true or false",
+				CategoryPresetAnnotation, secretName,
+			)
+		}
+		secretAnnotations[CategoryPresetAnnotation] = opts.CategoryPreset
 	}
 
 	// "Opaque" and "" secret types are treated the same by Kubernetes.
-	secret := BuildKubernetesSecretFromOnePasswordItem(secretName, namespace, secretAnnotations, labels,
-		secretType, *item, ownerRef, allowEmptyValues)
+	secret := BuildKubernetesSecretFromOnePasswordItem(secretName, namespace, secretAnnotations, opts.Labels,
+		opts.Type, *item, opts.OwnerRef, opts.AllowEmptyValues)
+	if err := AddKeystoreData(secret.Data, secretAnnotations, *item, opts.Keystore); err != nil {
+		return nil, fmt.Errorf("failed to build keystore for Secret %v: %w", secretName, err)
+	}
+	secretAnnotations[ContentHashAnnotation] = ContentHash(secret.Data)
 
 	currentSecret := &corev1.Secret{}
-	err := kubeClient.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, currentSecret)
+	err = kubeClient.Get(ctx, types.NamespacedName{Name: secret.Name, Namespace: secret.Namespace}, currentSecret)
 	if err != nil && apierrors.IsNotFound(err) {
 		log.Info(fmt.Sprintf("Creating Secret %v at namespace '%v'", secret.Name, secret.Namespace))
-		return kubeClient.Create(ctx, secret)
+		if err := kubeClient.Create(ctx, secret, kubernetesClient.FieldOwner(FieldManager)); err != nil {
+			return nil, err
+		}
+		return secret, nil
 	} else if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Check if the secret types are being changed on the update.
 	// Avoid Opaque and "" are treated as different on check.
-	wantSecretType := secretType
+	wantSecretType := opts.Type
 	if wantSecretType == "" {
 		wantSecretType = string(corev1.SecretTypeOpaque)
 	}
@@ -83,26 +121,32 @@ func CreateKubernetesSecretFromItem(
 		currentSecretType = string(corev1.SecretTypeOpaque)
 	}
 	if currentSecretType != wantSecretType {
-		return ErrCannotUpdateSecretType
+		return nil, ErrCannotUpdateSecretType
 	}
 
 	currentAnnotations := currentSecret.Annotations
 	currentLabels := currentSecret.Labels
-	if !reflect.DeepEqual(currentAnnotations, secretAnnotations) || !reflect.DeepEqual(currentLabels, labels) {
+	dataChanged := ContentHash(currentSecret.Data) != secretAnnotations[ContentHashAnnotation]
+	if dataChanged || !reflect.DeepEqual(currentAnnotations, secretAnnotations) || !reflect.DeepEqual(currentLabels, opts.Labels) {
+		if IsDrifted(currentSecret) {
+			log.Info(fmt.Sprintf("Secret %v at namespace '%v' was changed by %v, restoring it",
+				secret.Name, secret.Namespace, LastChangedBy(currentSecret),
+			))
+		}
 		log.Info(fmt.Sprintf("Updating Secret %v at namespace '%v'", secret.Name, secret.Namespace))
 		currentSecret.Annotations = secretAnnotations
-		currentSecret.Labels = labels
+		currentSecret.Labels = opts.Labels
 		currentSecret.Data = secret.Data
-		if err := kubeClient.Update(ctx, currentSecret); err != nil {
-			return fmt.Errorf("kubernetes secret update failed: %w", err)
+		if err := kubeClient.Update(ctx, currentSecret, kubernetesClient.FieldOwner(FieldManager)); err != nil {
+			return nil, fmt.Errorf("kubernetes secret update failed: %w", err)
 		}
-		return nil
+		return currentSecret, nil
 	}
 
 	log.Info(fmt.Sprintf("Secret with name %v and version %v already exists",
 		secret.Name, secret.Annotations[VersionAnnotation],
 	))
-	return nil
+	return nil, nil
 }
 
 func BuildKubernetesSecretFromOnePasswordItem(
@@ -121,19 +165,32 @@ func BuildKubernetesSecretFromOnePasswordItem(
 
 	return &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:            formatSecretName(name),
+			Name:            FormatSecretName(name),
 			Namespace:       namespace,
 			Annotations:     annotations,
 			Labels:          labels,
 			OwnerReferences: ownerRefs,
 		},
-		Data: BuildKubernetesSecretData(item.Fields, item.URLs, item.Files, allowEmptyValues),
+		Data: BuildKubernetesSecretDataForItem(item, allowEmptyValues, IsCategoryPresetEnabled(annotations)),
 		Type: corev1.SecretType(secretType),
 	}
 }
 
 func BuildKubernetesSecretData(
 	fields []model.ItemField, urls []model.ItemURL, files []model.File, allowEmptyValues bool,
+) map[string][]byte {
+	return buildKubernetesSecretData(fields, urls, files, allowEmptyValues, logSkipped)
+}
+
+// logSkipped is the skip callback used when building secret data, it only logs the message.
+func logSkipped(message string) {
+	log.Info(message)
+}
+
+// buildKubernetesSecretData builds the secret data and calls skip with a message for every
+// field, URL or file that is left out of it.
+func buildKubernetesSecretData(
+	fields []model.ItemField, urls []model.ItemURL, files []model.File, allowEmptyValues bool, skip func(string),
 ) map[string][]byte {
 	secretData := map[string][]byte{}
 
@@ -141,11 +198,11 @@ func BuildKubernetesSecretData(
 	for key, url := range urlsByLabel {
 		formattedKey := formatSecretDataName(key)
 		if formattedKey == "" {
-			log.Info(fmt.Sprintf("Skipping URL with invalid label %q because it must match [-._a-zA-Z0-9]+", url.Label))
+			skip(fmt.Sprintf("Skipping URL with invalid label %q because it must match [-._a-zA-Z0-9]+", url.Label))
 			continue
 		}
 		if emptyValueIsNotAllowed(allowEmptyValues, url.URL) {
-			log.Info(fmt.Sprintf(
+			skip(fmt.Sprintf(
 				"Skipping URL with empty value for label %q (use --allow-empty-values flag to include)",
 				url.Label,
 			))
@@ -157,11 +214,11 @@ func BuildKubernetesSecretData(
 	for i := 0; i < len(fields); i++ {
 		key := formatSecretDataName(fields[i].Label)
 		if key == "" {
-			log.Info(fmt.Sprintf("Skipping field with invalid label %q because it must match [-._a-zA-Z0-9]+", fields[i].Label))
+			skip(fmt.Sprintf("Skipping field with invalid label %q because it must match [-._a-zA-Z0-9]+", fields[i].Label))
 			continue
 		}
 		if emptyValueIsNotAllowed(allowEmptyValues, fields[i].Value) {
-			log.Info(fmt.Sprintf(
+			skip(fmt.Sprintf(
 				"Skipping field with empty value for label %q (use --allow-empty-values flag to include)",
 				fields[i].Label,
 			))
@@ -174,17 +231,18 @@ func BuildKubernetesSecretData(
 	for _, file := range files {
 		key := formatSecretDataName(file.Name)
 		if key == "" {
-			log.Info(fmt.Sprintf("Skipping file with invalid name %q because it must match [-._a-zA-Z0-9]+", file.Name))
+			skip(fmt.Sprintf("Skipping file with invalid name %q because it must match [-._a-zA-Z0-9]+", file.Name))
 			continue
 		}
 
 		content, err := file.Content()
 		if err != nil {
 			log.Error(err, fmt.Sprintf("Could not load contents of file %s", file.Name))
+			skip(fmt.Sprintf("Skipping file %q because its contents could not be loaded", file.Name))
 			continue
 		}
 		if emptyValueIsNotAllowed(allowEmptyValues, content) {
-			log.Info(
+			skip(
 				fmt.Sprintf(
 					"Skipping file with empty content for name %q (use --allow-empty-values flag to include)",
 					file.Name,
@@ -196,7 +254,7 @@ func BuildKubernetesSecretData(
 			if secretData[key] == nil {
 				secretData[key] = content
 			} else {
-				log.Info(fmt.Sprintf("File '%s' ignored because of a field with the same name", file.Name))
+				skip(fmt.Sprintf("File '%s' ignored because of a field with the same name", file.Name))
 			}
 		}
 	}
@@ -208,11 +266,11 @@ func emptyValueIsNotAllowed[T string | []byte](allowEmptyValues bool, value T) b
 	return !allowEmptyValues && len(value) == 0
 }
 
-// formatSecretName rewrites a value to be a valid Secret name.
+// FormatSecretName rewrites a value to be a valid Secret name.
 //
 // The Secret meta.name and data keys must be valid DNS subdomain names
 // (https://kubernetes.io/docs/concepts/configuration/secret/#overview-of-secrets)
-func formatSecretName(value string) string {
+func FormatSecretName(value string) string {
 	if errs := kubeValidate.IsDNS1123Subdomain(value); len(errs) == 0 {
 		return value
 	}
@@ -39,8 +39,12 @@ func TestCreateKubernetesSecretFromOnePasswordItem(t *testing.T) {
 	secretAnnotations := map[string]string{
 		"testAnnotation": "exists",
 	}
-	err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, restartDeploymentAnnotation,
-		secretLabels, secretAnnotations, secretType, nil, false)
+	_, err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, SecretOptions{
+		AutoRestart: restartDeploymentAnnotation,
+		Labels:      secretLabels,
+		Annotations: secretAnnotations,
+		Type:        secretType,
+	})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -78,8 +82,13 @@ func TestKubernetesSecretFromOnePasswordItemOwnerReferences(t *testing.T) {
 		Name:       "test-deployment",
 		UID:        types.UID("test-uid"),
 	}
-	err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, restartDeploymentAnnotation,
-		secretLabels, secretAnnotations, secretType, ownerRef, false)
+	_, err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, SecretOptions{
+		AutoRestart: restartDeploymentAnnotation,
+		Labels:      secretLabels,
+		Annotations: secretAnnotations,
+		Type:        secretType,
+		OwnerRef:    ownerRef,
+	})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -125,8 +134,12 @@ func TestUpdateKubernetesSecretFromOnePasswordItem(t *testing.T) {
 		"testAnnotation": "exists",
 	}
 
-	err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, restartDeploymentAnnotation,
-		secretLabels, secretAnnotations, secretType, nil, false)
+	_, err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, SecretOptions{
+		AutoRestart: restartDeploymentAnnotation,
+		Labels:      secretLabels,
+		Annotations: secretAnnotations,
+		Type:        secretType,
+	})
 
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
@@ -138,8 +151,12 @@ func TestUpdateKubernetesSecretFromOnePasswordItem(t *testing.T) {
 	newItem.Version = 456
 	newItem.VaultID = testVaultUUID
 	newItem.ID = testItemUUID
-	err = CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &newItem, restartDeploymentAnnotation,
-		secretLabels, secretAnnotations, secretType, nil, false)
+	_, err = CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &newItem, SecretOptions{
+		AutoRestart: restartDeploymentAnnotation,
+		Labels:      secretLabels,
+		Annotations: secretAnnotations,
+		Type:        secretType,
+	})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -390,8 +407,12 @@ func TestCreateKubernetesTLSSecretFromOnePasswordItem(t *testing.T) {
 		"testAnnotation": "exists",
 	}
 
-	err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, restartDeploymentAnnotation,
-		secretLabels, secretAnnotations, secretType, nil, false)
+	_, err := CreateKubernetesSecretFromItem(ctx, kubeClient, secretName, namespace, &item, SecretOptions{
+		AutoRestart: restartDeploymentAnnotation,
+		Labels:      secretLabels,
+		Annotations: secretAnnotations,
+		Type:        secretType,
+	})
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -25,6 +25,11 @@ func (tc *TestClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle st
 	return args.Get(0).([]model.Item), args.Error(1)
 }
 
+func (tc *TestClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	args := tc.Called(vaultID)
+	return args.Get(0).([]model.Item), args.Error(1)
+}
+
 func (tc *TestClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
 	args := tc.Called(vaultID, itemID, fileID)
 	if args.Get(0) == nil {
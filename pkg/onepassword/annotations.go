@@ -2,8 +2,8 @@ package onepassword
 
 import (
 	"regexp"
+	"strings"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 )
 
@@ -14,35 +14,48 @@ const (
 	VersionAnnotation             = OnepasswordPrefix + "/item-version"
 	RestartAnnotation             = OnepasswordPrefix + "/last-restarted"
 	AutoRestartWorkloadAnnotation = OnepasswordPrefix + "/auto-restart"
+	RestartPriorityAnnotation     = OnepasswordPrefix + "/restart-priority"
+	RestartLiveMountsAnnotation   = OnepasswordPrefix + "/restart-live-mounts"
+	RestartStrategyAnnotation     = OnepasswordPrefix + "/restart-strategy"
+	RestartCircuitOpenAnnotation  = OnepasswordPrefix + "/restart-circuit-open"
+	RestartRequiredAnnotation     = OnepasswordPrefix + "/restart-required"
+	InjectAnnotation              = OnepasswordPrefix + "/inject"
+	InjectionStatusAnnotation     = OnepasswordPrefix + "/status"
 )
 
-func GetAnnotationsForDeployment(deployment *appsv1.Deployment, regex *regexp.Regexp) (map[string]string, bool) {
-	annotationsFound := false
-	annotations := FilterAnnotations(deployment.Annotations, regex)
-	if len(annotations) > 0 {
-		annotationsFound = true
-	} else {
-		annotations = FilterAnnotations(deployment.Spec.Template.Annotations, regex)
-		if len(annotations) > 0 {
-			annotationsFound = true
-		} else {
-			annotationsFound = false
-		}
-	}
+// InjectLabel opts a pod into env var injection. The Pod webhook only receives pods labeled with
+// InjectLabelValue, so other pods are created without a round trip to the operator.
+const (
+	InjectLabel      = OnepasswordPrefix + "/inject"
+	InjectLabelValue = "true"
+)
 
-	return annotations, annotationsFound
-}
+// AnnotationRegExp matches the annotations workloads configure their item and restarts with.
+var AnnotationRegExp = regexp.MustCompile("^operator\\.1password\\.io\\/[a-zA-Z\\.]+")
 
 func FilterAnnotations(annotations map[string]string, regex *regexp.Regexp) map[string]string {
 	filteredAnnotations := make(map[string]string)
 	for key, value := range annotations {
-		if regex.MatchString(key) && key != RestartAnnotation && key != AutoRestartWorkloadAnnotation {
+		if regex.MatchString(key) && !isRestartAnnotation(key) && !isInjectAnnotation(key) {
 			filteredAnnotations[key] = value
 		}
 	}
 	return filteredAnnotations
 }
 
+// isRestartAnnotation reports whether key configures restarts rather than the secret of a workload.
+func isRestartAnnotation(key string) bool {
+	return key == RestartAnnotation || key == AutoRestartWorkloadAnnotation || key == RestartPriorityAnnotation ||
+		key == RestartLiveMountsAnnotation || key == RestartStrategyAnnotation ||
+		key == RestartCircuitOpenAnnotation || key == RestartRequiredAnnotation || strings.HasPrefix(key, SecretChecksumAnnotation(""))
+}
+
+// isInjectAnnotation reports whether key configures the env var injection of the Pod webhook, which
+// reads secrets from 1Password when pods are created rather than through a Kubernetes secret.
+func isInjectAnnotation(key string) bool {
+	return key == InjectAnnotation || key == InjectionStatusAnnotation
+}
+
 func AreAnnotationsUsingSecrets(annotations map[string]string, secrets map[string]*corev1.Secret) bool {
 	_, ok := secrets[annotations[NameAnnotation]]
 	return ok
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 )
 
 const AnnotationRegExpString = "^operator\\.1password\\.io\\/[a-zA-Z\\.]+"
@@ -16,6 +17,12 @@ func TestFilterAnnotations(t *testing.T) {
 	annotations := getValidAnnotations()
 	annotations[invalidAnnotation1] = "This should be filtered"
 	annotations[invalidAnnotation2] = "This should be filtered too"
+	annotations[AutoRestartWorkloadAnnotation] = "true"
+	annotations[RestartPriorityAnnotation] = "10"
+	annotations[RestartLiveMountsAnnotation] = "true"
+	annotations[RestartStrategyAnnotation] = "evict"
+	annotations[RestartRequiredAnnotation] = "2006-01-02T15:04:05Z"
+	annotations[SecretChecksumAnnotation("secret")] = "checksum"
 
 	r, _ := regexp.Compile(AnnotationRegExpString)
 	filteredAnnotations := FilterAnnotations(annotations, r)
@@ -39,7 +46,7 @@ func TestGetTopLevelAnnotationsForDeployment(t *testing.T) {
 
 	deployment := &appsv1.Deployment{}
 	deployment.Annotations = annotations
-	filteredAnnotations, annotationsFound := GetAnnotationsForDeployment(deployment, r)
+	filteredAnnotations, annotationsFound := GetAnnotationsForWorkload(deployment, r)
 
 	if !annotationsFound {
 		t.Errorf("No annotations marked as found")
@@ -58,7 +65,7 @@ func TestGetTemplateAnnotationsForDeployment(t *testing.T) {
 
 	deployment := &appsv1.Deployment{}
 	deployment.Spec.Template.Annotations = annotations
-	filteredAnnotations, annotationsFound := GetAnnotationsForDeployment(deployment, r)
+	filteredAnnotations, annotationsFound := GetAnnotationsForWorkload(deployment, r)
 
 	if !annotationsFound {
 		t.Errorf("No annotations marked as found")
@@ -73,7 +80,7 @@ func TestGetTemplateAnnotationsForDeployment(t *testing.T) {
 func TestGetNoAnnotationsForDeployment(t *testing.T) {
 	deployment := &appsv1.Deployment{}
 	r, _ := regexp.Compile(AnnotationRegExpString)
-	filteredAnnotations, annotationsFound := GetAnnotationsForDeployment(deployment, r)
+	filteredAnnotations, annotationsFound := GetAnnotationsForWorkload(deployment, r)
 
 	if annotationsFound {
 		t.Errorf("No annotations should be found")
@@ -85,9 +92,46 @@ func TestGetNoAnnotationsForDeployment(t *testing.T) {
 	}
 }
 
+func TestGetNoAnnotationsForInjectOnlyDeployment(t *testing.T) {
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Annotations = map[string]string{
+		InjectAnnotation:          "app",
+		InjectionStatusAnnotation: "injected",
+	}
+	r, _ := regexp.Compile(AnnotationRegExpString)
+	filteredAnnotations, annotationsFound := GetAnnotationsForWorkload(deployment, r)
+
+	if annotationsFound {
+		t.Errorf("Inject annotations should not mark the workload as annotated")
+	}
+
+	numAnnotations := len(filteredAnnotations)
+	if numAnnotations != 0 {
+		t.Errorf("Expected %v annotations got %v", 0, numAnnotations)
+	}
+}
+
 func getValidAnnotations() map[string]string {
 	return map[string]string{
 		ItemPathAnnotation: "vaults/b3e4c7fc-8bf7-4c22-b8bb-147539f10e4f/items/b3e4c7fc-8bf7-4c22-b8bb-147539f10e4f",
 		NameAnnotation:     "secretName",
 	}
 }
+
+func TestGetTemplateAnnotationsForCronJob(t *testing.T) {
+	annotations := getValidAnnotations()
+	r, _ := regexp.Compile(AnnotationRegExpString)
+
+	cronJob := &batchv1.CronJob{}
+	cronJob.Spec.JobTemplate.Spec.Template.Annotations = annotations
+	filteredAnnotations, annotationsFound := GetAnnotationsForWorkload(cronJob, r)
+
+	if !annotationsFound {
+		t.Errorf("No annotations marked as found")
+	}
+
+	numAnnotations := len(filteredAnnotations)
+	if len(annotations) != numAnnotations {
+		t.Errorf("Expected %v annotations got %v", len(annotations), numAnnotations)
+	}
+}
@@ -0,0 +1,173 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/sync/singleflight"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+// DefaultTitleCacheTTL is how long vault and item title lookups are cached unless configured otherwise.
+const DefaultTitleCacheTTL = 5 * time.Minute
+
+const (
+	vaultsCache = "vaults"
+	itemsCache  = "items"
+)
+
+var (
+	titleCacheHitsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_title_cache_hits_total",
+		Help: "Number of vault and item title lookups answered from the cache.",
+	}, []string{"cache"})
+	titleCacheMissesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_title_cache_misses_total",
+		Help: "Number of vault and item title lookups sent to 1Password.",
+	}, []string{"cache"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(titleCacheHitsTotal, titleCacheMissesTotal)
+}
+
+type cacheEntry[T any] struct {
+	value   []T
+	expires time.Time
+}
+
+type itemTitleKey struct {
+	vaultID string
+	title   string
+}
+
+// titleCacheClient caches the vaults and items found by title, so resolving a title to an ID does not
+// list vaults and items on every request. Empty results and errors are not cached, and entries
+// referencing a vault or item that is no longer found are dropped.
+type titleCacheClient struct {
+	client Client
+	ttl    time.Duration
+	now    func() time.Time
+
+	mu     sync.Mutex
+	vaults map[string]cacheEntry[model.Vault]
+	items  map[itemTitleKey]cacheEntry[model.Item]
+	group  singleflight.Group
+}
+
+func newTitleCacheClient(client Client, ttl time.Duration) *titleCacheClient {
+	return &titleCacheClient{
+		client: client,
+		ttl:    ttl,
+		now:    time.Now,
+		vaults: map[string]cacheEntry[model.Vault]{},
+		items:  map[itemTitleKey]cacheEntry[model.Item]{},
+	}
+}
+
+func (c *titleCacheClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	item, err := c.client.GetItemByID(ctx, vaultID, itemID)
+	if errors.Is(err, ErrNotFound) {
+		c.invalidate(vaultID, itemID)
+	}
+	return item, err
+}
+
+func (c *titleCacheClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	key := itemTitleKey{vaultID: vaultID, title: itemTitle}
+	c.mu.Lock()
+	entry, ok := c.items[key]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		titleCacheHitsTotal.WithLabelValues(itemsCache).Inc()
+		return entry.value, nil
+	}
+
+	titleCacheMissesTotal.WithLabelValues(itemsCache).Inc()
+	result, err, _ := c.group.Do(itemsCache+"/"+vaultID+"/"+itemTitle, func() (any, error) {
+		items, err := c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				c.invalidate(vaultID, "")
+			}
+			return nil, err
+		}
+		if len(items) > 0 {
+			c.mu.Lock()
+			c.items[key] = cacheEntry[model.Item]{value: items, expires: c.now().Add(c.ttl)}
+			c.mu.Unlock()
+		}
+		return items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]model.Item), nil
+}
+
+func (c *titleCacheClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	items, err := c.client.ListItems(ctx, vaultID)
+	if errors.Is(err, ErrNotFound) {
+		c.invalidate(vaultID, "")
+	}
+	return items, err
+}
+
+func (c *titleCacheClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	return c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+}
+
+func (c *titleCacheClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	c.mu.Lock()
+	entry, ok := c.vaults[title]
+	c.mu.Unlock()
+	if ok && c.now().Before(entry.expires) {
+		titleCacheHitsTotal.WithLabelValues(vaultsCache).Inc()
+		return entry.value, nil
+	}
+
+	titleCacheMissesTotal.WithLabelValues(vaultsCache).Inc()
+	result, err, _ := c.group.Do(vaultsCache+"/"+title, func() (any, error) {
+		vaults, err := c.client.GetVaultsByTitle(ctx, title)
+		if err != nil {
+			return nil, err
+		}
+		if len(vaults) > 0 {
+			c.mu.Lock()
+			c.vaults[title] = cacheEntry[model.Vault]{value: vaults, expires: c.now().Add(c.ttl)}
+			c.mu.Unlock()
+		}
+		return vaults, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.([]model.Vault), nil
+}
+
+// invalidate drops the cached vaults with vaultID, and the cached items in that vault with itemID.
+// An empty itemID drops all cached items in the vault.
+func (c *titleCacheClient) invalidate(vaultID, itemID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for title, entry := range c.vaults {
+		if slices.ContainsFunc(entry.value, func(vault model.Vault) bool { return vault.ID == vaultID }) {
+			delete(c.vaults, title)
+		}
+	}
+	for key, entry := range c.items {
+		if key.vaultID != vaultID {
+			continue
+		}
+		if itemID == "" || slices.ContainsFunc(entry.value, func(item model.Item) bool { return item.ID == itemID }) {
+			delete(c.items, key)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+func TestTitleCacheClient_GetVaultsByTitle(t *testing.T) {
+	vaults := []model.Vault{{ID: "vault-id"}}
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetVaultsByTitle", "Employee").Return(vaults, nil)
+	mockClient.On("GetVaultsByTitle", "Unknown").Return([]model.Vault{}, nil)
+
+	now := time.Now()
+	c := newTitleCacheClient(mockClient, time.Minute)
+	c.now = func() time.Time { return now }
+
+	hits := testutil.ToFloat64(titleCacheHitsTotal.WithLabelValues(vaultsCache))
+	misses := testutil.ToFloat64(titleCacheMissesTotal.WithLabelValues(vaultsCache))
+
+	for i := 0; i < 3; i++ {
+		result, err := c.GetVaultsByTitle(context.Background(), "Employee")
+		require.NoError(t, err)
+		require.Equal(t, vaults, result)
+	}
+	mockClient.AssertNumberOfCalls(t, "GetVaultsByTitle", 1)
+	require.Equal(t, hits+2, testutil.ToFloat64(titleCacheHitsTotal.WithLabelValues(vaultsCache)))
+	require.Equal(t, misses+1, testutil.ToFloat64(titleCacheMissesTotal.WithLabelValues(vaultsCache)))
+
+	now = now.Add(time.Minute)
+	_, err := c.GetVaultsByTitle(context.Background(), "Employee")
+	require.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "GetVaultsByTitle", 2)
+
+	// Empty results are not cached, so a vault is found as soon as it is created.
+	for i := 0; i < 2; i++ {
+		_, err := c.GetVaultsByTitle(context.Background(), "Unknown")
+		require.NoError(t, err)
+	}
+	mockClient.AssertNumberOfCalls(t, "GetVaultsByTitle", 4)
+}
+
+func TestTitleCacheClient_GetItemsByTitle(t *testing.T) {
+	items := []model.Item{{ID: "item-id", VaultID: "vault-id"}}
+	testCases := map[string]struct {
+		setup         func(mockClient *mocks.TestClient)
+		between       func(t *testing.T, c *titleCacheClient)
+		expectedCalls int
+	}{
+		"cached": {
+			between:       func(t *testing.T, c *titleCacheClient) {},
+			expectedCalls: 1,
+		},
+		"item not found": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetItemByID", "vault-id", "item-id").
+					Return(nil, fmt.Errorf("item deleted: %w", ErrNotFound))
+			},
+			between: func(t *testing.T, c *titleCacheClient) {
+				_, err := c.GetItemByID(context.Background(), "vault-id", "item-id")
+				require.ErrorIs(t, err, ErrNotFound)
+			},
+			expectedCalls: 2,
+		},
+		"other item not found": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetItemByID", "vault-id", "other-item-id").
+					Return(nil, fmt.Errorf("item deleted: %w", ErrNotFound))
+			},
+			between: func(t *testing.T, c *titleCacheClient) {
+				_, err := c.GetItemByID(context.Background(), "vault-id", "other-item-id")
+				require.ErrorIs(t, err, ErrNotFound)
+			},
+			expectedCalls: 1,
+		},
+		"item found": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetItemByID", "vault-id", "item-id").Return(&items[0], nil)
+			},
+			between: func(t *testing.T, c *titleCacheClient) {
+				_, err := c.GetItemByID(context.Background(), "vault-id", "item-id")
+				require.NoError(t, err)
+			},
+			expectedCalls: 1,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			mockClient := &mocks.TestClient{}
+			mockClient.On("GetItemsByTitle", "vault-id", "Database").Return(items, nil)
+			if tc.setup != nil {
+				tc.setup(mockClient)
+			}
+			c := newTitleCacheClient(mockClient, time.Minute)
+
+			result, err := c.GetItemsByTitle(context.Background(), "vault-id", "Database")
+			require.NoError(t, err)
+			require.Equal(t, items, result)
+
+			tc.between(t, c)
+
+			result, err = c.GetItemsByTitle(context.Background(), "vault-id", "Database")
+			require.NoError(t, err)
+			require.Equal(t, items, result)
+			mockClient.AssertNumberOfCalls(t, "GetItemsByTitle", tc.expectedCalls)
+		})
+	}
+}
+
+func TestTitleCacheClient_ErrorsAreNotCached(t *testing.T) {
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetItemsByTitle", "vault-id", "Database").
+		Return([]model.Item{}, fmt.Errorf("too many requests: %w", ErrRateLimited)).Once()
+	mockClient.On("GetItemsByTitle", "vault-id", "Database").Return([]model.Item{{ID: "item-id"}}, nil)
+
+	c := newTitleCacheClient(mockClient, time.Minute)
+	_, err := c.GetItemsByTitle(context.Background(), "vault-id", "Database")
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	result, err := c.GetItemsByTitle(context.Background(), "vault-id", "Database")
+	require.NoError(t, err)
+	require.Equal(t, []model.Item{{ID: "item-id"}}, result)
+}
@@ -1,13 +1,19 @@
 package client
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"os"
+	"slices"
+	"time"
 
 	"github.com/go-logr/logr"
 
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/client/connect"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/client/sdk"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
 )
@@ -16,13 +22,52 @@ import (
 type Client interface {
 	GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error)
 	GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error)
+	// ListItems lists the items of a vault without their fields and files. Items listed with a
+	// Service Account have no version, their UpdatedAt tells whether they changed.
+	ListItems(ctx context.Context, vaultID string) ([]model.Item, error)
 	GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error)
 	GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error)
 }
 
+// Errors returned by every Client implementation, see package clienterrors.
+var (
+	ErrNotFound     = clienterrors.ErrNotFound
+	ErrUnauthorized = clienterrors.ErrUnauthorized
+	ErrRateLimited  = clienterrors.ErrRateLimited
+	ErrUnavailable  = clienterrors.ErrUnavailable
+)
+
+// RateLimitError is returned when requests are rate limited. It matches ErrRateLimited.
+type RateLimitError = clienterrors.RateLimitError
+
+// RetryAfter returns the retry delay of a rate limit error in the chain of err.
+// It returns false when err is not rate limited or 1Password did not tell when to retry.
+func RetryAfter(err error) (time.Duration, bool) {
+	return clienterrors.RetryAfter(err)
+}
+
+// IsTransient reports whether retrying the request that failed with err may succeed
+// without anything changing in 1Password or in the operator configuration.
+func IsTransient(err error) bool {
+	return clienterrors.IsTransient(err)
+}
+
 type Config struct {
 	Logger  logr.Logger
 	Version string
+	// ConnectRetryPolicy overrides the retry policy of requests to Connect when set.
+	ConnectRetryPolicy retry.Policy
+	// SDKRetryPolicy overrides the retry policy of requests made with a service account when set.
+	SDKRetryPolicy retry.Policy
+	// ConnectRateLimit overrides the client-side rate limit of requests to Connect when set.
+	ConnectRateLimit ratelimit.Limit
+	// SDKRateLimit overrides the client-side rate limit of requests made with a service account when set.
+	SDKRateLimit ratelimit.Limit
+	// TitleCacheTTL is how long vaults and items found by title are cached, DefaultTitleCacheTTL when zero.
+	// A negative TTL disables the cache.
+	TitleCacheTTL time.Duration
+	// Middlewares are wrapped around the client built from the configuration, the first being the outermost.
+	Middlewares []Middleware
 }
 
 // NewFromEnvironment creates a new 1Password client based on the provided configuration.
@@ -37,20 +82,48 @@ func NewFromEnvironment(ctx context.Context, cfg Config) (Client, error) {
 
 	if serviceAccountToken != "" {
 		cfg.Logger.Info("Using Service Account Token")
-		return sdk.NewClient(ctx, sdk.Config{
+		sdkClient, err := sdk.NewClient(ctx, sdk.Config{
 			ServiceAccountToken: serviceAccountToken,
 			IntegrationName:     "1password-operator",
 			IntegrationVersion:  cfg.Version,
 		})
+		if err != nil {
+			return nil, err
+		}
+		return Chain(sdkClient, cfg.middlewares(BackendSDK,
+			cmp.Or(cfg.SDKRetryPolicy, sdk.DefaultRetryPolicy()),
+			cmp.Or(cfg.SDKRateLimit, sdk.DefaultRateLimit()),
+		)...), nil
 	}
 
 	if connectHost != "" && connectToken != "" {
 		cfg.Logger.Info("Using 1Password Connect")
-		return connect.NewClient(connect.Config{
+		connectClient := connect.NewClient(connect.Config{
 			ConnectHost:  connectHost,
 			ConnectToken: connectToken,
-		}), nil
+		})
+		return Chain(connectClient, cfg.middlewares(BackendConnect,
+			cmp.Or(cfg.ConnectRetryPolicy, connect.DefaultRetryPolicy()),
+			cmp.Or(cfg.ConnectRateLimit, connect.DefaultRateLimit()),
+		)...), nil
 	}
 
 	return nil, errors.New("invalid configuration. Connect or Service Account credentials should be set")
 }
+
+// middlewares returns the middlewares wrapped around the backend client. Every request is traced,
+// including those answered from the cache. Cached lookups skip the retries and the rate limiter,
+// every retry waits for the rate limiter, and only requests actually sent to 1Password are
+// logged and counted in the request metrics.
+func (cfg Config) middlewares(backend string, retryPolicy retry.Policy, limit ratelimit.Limit) []Middleware {
+	middlewares := append(slices.Clone(cfg.Middlewares), Tracing(backend))
+	if cfg.TitleCacheTTL >= 0 {
+		middlewares = append(middlewares, TitleCache(cmp.Or(cfg.TitleCacheTTL, DefaultTitleCacheTTL)))
+	}
+	return append(middlewares,
+		Retry(retryPolicy),
+		RateLimit(limit),
+		Metrics(backend),
+		Logging(cfg.Logger),
+	)
+}
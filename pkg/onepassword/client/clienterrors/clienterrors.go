@@ -0,0 +1,94 @@
+// Package clienterrors defines the errors returned by the 1Password client backends.
+// The backends wrap the errors of the Connect API and the SDK with one of the sentinel
+// errors below, so that callers can tell them apart with errors.Is.
+package clienterrors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned when a vault, item or file does not exist or is not accessible.
+	ErrNotFound = errors.New("not found in 1Password")
+	// ErrUnauthorized is returned when the credentials are invalid or lack access.
+	ErrUnauthorized = errors.New("unauthorized by 1Password")
+	// ErrRateLimited is returned when 1Password rejects requests because of rate limits.
+	// The error chain contains a *RateLimitError telling when to retry, if known.
+	ErrRateLimited = errors.New("1Password rate limit exceeded")
+	// ErrUnavailable is returned when 1Password cannot be reached or fails to handle a request.
+	ErrUnavailable = errors.New("1Password is unavailable")
+)
+
+// RateLimitError is returned when requests are rate limited. It matches ErrRateLimited.
+type RateLimitError struct {
+	// RetryAfter is how long to wait before retrying, zero when 1Password did not tell.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("%s, retry after %s: %s", ErrRateLimited, e.RetryAfter, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", ErrRateLimited, e.Err)
+}
+
+func (e *RateLimitError) Unwrap() []error {
+	return []error{ErrRateLimited, e.Err}
+}
+
+// Wrap marks err with one of the sentinel errors, keeping err in the chain.
+func Wrap(sentinel, err error) error {
+	if err == nil {
+		return nil
+	}
+	if sentinel == ErrRateLimited {
+		return &RateLimitError{Err: err}
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// WrapRateLimited marks err as rate limited, with how long 1Password asked to wait before retrying,
+// zero if it did not tell.
+func WrapRateLimited(err error, retryAfter time.Duration) error {
+	if err == nil {
+		return nil
+	}
+	return &RateLimitError{RetryAfter: retryAfter, Err: err}
+}
+
+// RetryAfter returns the retry delay of a rate limit error in the chain of err.
+// It returns false when err is not rate limited or 1Password did not tell when to retry.
+func RetryAfter(err error) (time.Duration, bool) {
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) && rateLimitErr.RetryAfter > 0 {
+		return rateLimitErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// Reason returns a short label for the kind of err, for use in metrics.
+func Reason(err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrUnavailable):
+		return "unavailable"
+	default:
+		return "unknown"
+	}
+}
+
+// IsTransient reports whether retrying the request that failed with err may succeed
+// without anything changing in 1Password or in the operator configuration.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable)
+}
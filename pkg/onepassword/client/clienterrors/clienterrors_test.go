@@ -0,0 +1,60 @@
+package clienterrors
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrap(t *testing.T) {
+	backendErr := errors.New("backend error")
+
+	for _, sentinel := range []error{ErrNotFound, ErrUnauthorized, ErrRateLimited, ErrUnavailable} {
+		err := fmt.Errorf("failed to get item: %w", Wrap(sentinel, backendErr))
+		require.ErrorIs(t, err, sentinel)
+		require.ErrorIs(t, err, backendErr)
+	}
+	require.NoError(t, Wrap(ErrNotFound, nil))
+}
+
+func TestRetryAfter(t *testing.T) {
+	backendErr := errors.New("backend error")
+
+	_, ok := RetryAfter(Wrap(ErrRateLimited, backendErr))
+	require.False(t, ok)
+	_, ok = RetryAfter(Wrap(ErrUnavailable, backendErr))
+	require.False(t, ok)
+
+	err := fmt.Errorf("failed to get item: %w", &RateLimitError{RetryAfter: time.Minute, Err: backendErr})
+	require.ErrorIs(t, err, ErrRateLimited)
+	retryAfter, ok := RetryAfter(err)
+	require.True(t, ok)
+	require.Equal(t, time.Minute, retryAfter)
+}
+
+func TestReason(t *testing.T) {
+	backendErr := errors.New("backend error")
+
+	testCases := map[string]struct {
+		err       error
+		reason    string
+		transient bool
+	}{
+		"nil":          {err: nil, reason: ""},
+		"not found":    {err: Wrap(ErrNotFound, backendErr), reason: "not_found"},
+		"unauthorized": {err: Wrap(ErrUnauthorized, backendErr), reason: "unauthorized"},
+		"rate limited": {err: Wrap(ErrRateLimited, backendErr), reason: "rate_limited", transient: true},
+		"unavailable":  {err: Wrap(ErrUnavailable, backendErr), reason: "unavailable", transient: true},
+		"unknown":      {err: backendErr, reason: "unknown"},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			require.Equal(t, tc.reason, Reason(tc.err))
+			require.Equal(t, tc.transient, IsTransient(tc.err))
+		})
+	}
+}
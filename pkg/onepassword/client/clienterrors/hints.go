@@ -0,0 +1,98 @@
+package clienterrors
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryAfterHints is an http.RoundTripper recording the Retry-After header of rate limited responses,
+// so that the errors of backends that don't expose the header can still tell when to retry.
+type RetryAfterHints struct {
+	next http.RoundTripper
+
+	mu sync.Mutex
+	// retryAt is when each host allows requests again, as of its last rate limited response.
+	retryAt map[string]time.Time
+	// lastHost is the host of the last rate limited response.
+	lastHost string
+}
+
+var (
+	defaultHints     *RetryAfterHints
+	defaultHintsOnce sync.Once
+)
+
+// DefaultRetryAfterHints returns the hints recorded from the responses received by http.DefaultClient,
+// which both the Connect SDK and the 1Password SDK send their requests with. The recording transport is
+// installed on http.DefaultClient on the first call, and passes requests on to its previous transport.
+func DefaultRetryAfterHints() *RetryAfterHints {
+	defaultHintsOnce.Do(func() {
+		next := http.DefaultClient.Transport
+		if next == nil {
+			next = http.DefaultTransport
+		}
+		defaultHints = NewRetryAfterHints(next)
+		http.DefaultClient.Transport = defaultHints
+	})
+	return defaultHints
+}
+
+// NewRetryAfterHints returns hints recording the responses of next.
+func NewRetryAfterHints(next http.RoundTripper) *RetryAfterHints {
+	return &RetryAfterHints{next: next, retryAt: map[string]time.Time{}}
+}
+
+func (h *RetryAfterHints) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := h.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusTooManyRequests {
+		return resp, err
+	}
+
+	// Responses without a hint drop the hint of earlier responses.
+	retryAt := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.retryAt[req.URL.Host] = retryAt
+	h.lastHost = req.URL.Host
+	return resp, nil
+}
+
+// RetryAfter returns how long the last rate limited response of host asked to wait from now on, or of
+// any host if host is empty. It returns zero when there was no hint or the wait is over.
+func (h *RetryAfterHints) RetryAfter(host string) time.Duration {
+	if h == nil {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if host == "" {
+		host = h.lastHost
+	}
+	retryAt, found := h.retryAt[host]
+	if !found {
+		return 0
+	}
+	return max(time.Until(retryAt), 0)
+}
+
+// parseRetryAfter returns the time a Retry-After header value, in seconds or as an HTTP date, allows
+// requests again. It returns the zero time if the value is empty or invalid.
+func parseRetryAfter(value string, now time.Time) time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds <= 0 {
+			return time.Time{}
+		}
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return date
+	}
+	return time.Time{}
+}
@@ -0,0 +1,72 @@
+package clienterrors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := map[string]struct {
+		value    string
+		expected time.Time
+	}{
+		"seconds":  {value: "30", expected: now.Add(30 * time.Second)},
+		"date":     {value: "Wed, 01 Jan 2025 12:05:00 GMT", expected: now.Add(5 * time.Minute)},
+		"empty":    {value: ""},
+		"negative": {value: "-5"},
+		"invalid":  {value: "soon"},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			require.True(t, tc.expected.Equal(parseRetryAfter(tc.value, now)))
+		})
+	}
+}
+
+func TestRetryAfterHints(t *testing.T) {
+	retryAfter := "60"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ok" {
+			return
+		}
+		if retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+
+	hints := NewRetryAfterHints(http.DefaultTransport)
+	client := &http.Client{Transport: hints}
+	get := func(path string) {
+		resp, err := client.Get(server.URL + path)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+
+	require.Zero(t, hints.RetryAfter(serverURL.Host))
+
+	get("/limited")
+	require.InDelta(t, time.Minute, hints.RetryAfter(serverURL.Host), float64(5*time.Second))
+	require.InDelta(t, time.Minute, hints.RetryAfter(""), float64(5*time.Second))
+	require.Zero(t, hints.RetryAfter("other:8080"))
+
+	// Successful responses keep the hint, rate limited responses without one drop it.
+	get("/ok")
+	require.NotZero(t, hints.RetryAfter(serverURL.Host))
+	retryAfter = ""
+	get("/limited")
+	require.Zero(t, hints.RetryAfter(serverURL.Host))
+
+	require.Zero(t, (*RetryAfterHints)(nil).RetryAfter(""))
+}
@@ -2,13 +2,16 @@ package connect
 
 import (
 	"context"
-	"errors"
 	"fmt"
+	"net/url"
 	"strings"
 	"time"
 
 	"github.com/1Password/connect-sdk-go/connect"
 	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
 )
 
@@ -18,22 +21,49 @@ type Config struct {
 	ConnectToken string
 }
 
+// DefaultRateLimit returns the client-side rate limit of requests to Connect. Connect serves
+// requests from its local copy of the vaults, so they are not limited.
+func DefaultRateLimit() ratelimit.Limit {
+	return ratelimit.Unlimited
+}
+
+// DefaultRetryPolicy returns the retry policy of the Connect client. Connect answers requests for
+// files it has not synchronized yet with a server error, so those are retried for a few seconds.
+func DefaultRetryPolicy() retry.Policy {
+	return retry.Policy{
+		InitialInterval: 500 * time.Millisecond,
+		MaxInterval:     30 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxAttempts:     5,
+	}
+}
+
 // Connect is a client for interacting with 1Password using the Connect API.
 type Connect struct {
 	client connect.Client
+	// hints are the Retry-After hints of the responses of Connect, found under host.
+	hints *clienterrors.RetryAfterHints
+	host  string
 }
 
 // NewClient creates a new Connect client using provided configuration.
 func NewClient(config Config) *Connect {
+	var host string
+	if connectURL, err := url.Parse(config.ConnectHost); err == nil {
+		host = connectURL.Host
+	}
 	return &Connect{
 		client: connect.NewClient(config.ConnectHost, config.ConnectToken),
+		hints:  clienterrors.DefaultRetryAfterHints(),
+		host:   host,
 	}
 }
 
 func (c *Connect) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
 	connectItem, err := c.client.GetItemByUUID(itemID, vaultID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetItemByID using 1Password Connect: %w", err)
+		return nil, fmt.Errorf("failed to GetItemByID using 1Password Connect: %w", wrapError(err, c.hints, c.host))
 	}
 
 	var item model.Item
@@ -45,7 +75,7 @@ func (c *Connect) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string
 	// Get all items in the vault with the specified title
 	connectItems, err := c.client.GetItemsByTitle(itemTitle, vaultID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password Connect: %w", err)
+		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password Connect: %w", wrapError(err, c.hints, c.host))
 	}
 
 	items := make([]model.Item, len(connectItems))
@@ -58,39 +88,36 @@ func (c *Connect) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string
 	return items, nil
 }
 
-// GetFileContent retrieves the content of a file from a 1Password item.
-// As the Connect has a delay when synchronizing files and returns a 500 error in this case,
-// this function implements a retry mechanism.
-func (c *Connect) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
-	const maxRetries = 5
-	const delay = 1 * time.Second
-
-	var lastErr error
-	for i := 0; i < maxRetries; i++ {
-		bytes, err := c.client.GetFileContent(&onepassword.File{
-			ContentPath: fmt.Sprintf("/v1/vaults/%s/items/%s/files/%s/content", vaultID, itemID, fileID),
-		})
-		if err == nil {
-			return bytes, nil
-		}
-
-		var connectErr *onepassword.Error
-		if errors.As(err, &connectErr) && connectErr.StatusCode == 500 {
-			lastErr = err
-			time.Sleep(delay)
-			continue
-		}
+func (c *Connect) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	connectItems, err := c.client.GetItems(vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListItems using 1Password Connect: %w", wrapError(err, c.hints, c.host))
+	}
 
-		return nil, fmt.Errorf("failed to GetFileContent using 1Password Connect: %w", err)
+	items := make([]model.Item, len(connectItems))
+	for i, connectItem := range connectItems {
+		items[i].FromConnectItem(&connectItem)
 	}
+	return items, nil
+}
 
-	return nil, fmt.Errorf("failed to GetFileContent using 1Password Connect after %d retries: %w", maxRetries, lastErr)
+// GetFileContent retrieves the content of a file from a 1Password item.
+// Connect has a delay when synchronizing files and returns a 500 error in the meantime,
+// which is returned as ErrUnavailable so that it is retried.
+func (c *Connect) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	bytes, err := c.client.GetFileContent(&onepassword.File{
+		ContentPath: fmt.Sprintf("/v1/vaults/%s/items/%s/files/%s/content", vaultID, itemID, fileID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to GetFileContent using 1Password Connect: %w", wrapError(err, c.hints, c.host))
+	}
+	return bytes, nil
 }
 
 func (c *Connect) GetVaultsByTitle(ctx context.Context, vaultQuery string) ([]model.Vault, error) {
 	connectVaults, err := c.client.GetVaultsByTitle(vaultQuery)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetVaultsByTitle using 1Password Connect: %w", err)
+		return nil, fmt.Errorf("failed to GetVaultsByTitle using 1Password Connect: %w", wrapError(err, c.hints, c.host))
 	}
 
 	var vaults []model.Vault
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
 	clienttesting "github.com/1Password/onepassword-operator/pkg/onepassword/client/testing"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/client/testing/mock"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
@@ -118,6 +119,53 @@ func TestConnect_GetItemsByTitle(t *testing.T) {
 	}
 }
 
+func TestConnect_ListItems(t *testing.T) {
+	connectItem1 := clienttesting.CreateConnectItem()
+	connectItem2 := clienttesting.CreateConnectItem()
+
+	testCases := map[string]struct {
+		mockClient func() *mock.ConnectClientMock
+		check      func(t *testing.T, items []model.Item, err error)
+	}{
+		"should return all items": {
+			mockClient: func() *mock.ConnectClientMock {
+				mockConnectClient := &mock.ConnectClientMock{}
+				mockConnectClient.On("GetItems", "vault-id").Return(
+					[]onepassword.Item{
+						*connectItem1,
+						*connectItem2,
+					}, nil)
+				return mockConnectClient
+			},
+			check: func(t *testing.T, items []model.Item, err error) {
+				require.NoError(t, err)
+				require.Len(t, items, 2)
+				clienttesting.CheckConnectItemMapping(t, connectItem1, &items[0])
+				clienttesting.CheckConnectItemMapping(t, connectItem2, &items[1])
+			},
+		},
+		"should return an error": {
+			mockClient: func() *mock.ConnectClientMock {
+				mockConnectClient := &mock.ConnectClientMock{}
+				mockConnectClient.On("GetItems", "vault-id").Return([]onepassword.Item{}, errors.New("error"))
+				return mockConnectClient
+			},
+			check: func(t *testing.T, items []model.Item, err error) {
+				require.Error(t, err)
+				require.Nil(t, items)
+			},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			client := &Connect{client: tc.mockClient()}
+			items, err := client.ListItems(context.Background(), "vault-id")
+			tc.check(t, items, err)
+		})
+	}
+}
+
 func TestConnect_GetFileContent(t *testing.T) {
 	testCases := map[string]struct {
 		mockClient func() *mock.ConnectClientMock
@@ -136,6 +184,19 @@ func TestConnect_GetFileContent(t *testing.T) {
 				require.Equal(t, []byte("file content"), content)
 			},
 		},
+		"should return a transient error while the file is synchronized": {
+			mockClient: func() *mock.ConnectClientMock {
+				mockConnectClient := &mock.ConnectClientMock{}
+				mockConnectClient.On("GetFileContent", &onepassword.File{
+					ContentPath: "/v1/vaults/vault-id/items/item-id/files/file-id/content",
+				}).Return(nil, &onepassword.Error{StatusCode: 500, Message: "file not synchronized"})
+				return mockConnectClient
+			},
+			check: func(t *testing.T, content []byte, err error) {
+				require.ErrorIs(t, err, clienterrors.ErrUnavailable)
+				require.Nil(t, content)
+			},
+		},
 		"should return an error": {
 			mockClient: func() *mock.ConnectClientMock {
 				mockConnectClient := &mock.ConnectClientMock{}
@@ -0,0 +1,41 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+)
+
+// wrapError marks an error returned by the Connect API with the matching clienterrors sentinel,
+// based on the HTTP status code of the response. Rate limited errors carry the Retry-After hint
+// that hints recorded for host, as the Connect SDK does not expose the headers of responses.
+func wrapError(err error, hints *clienterrors.RetryAfterHints, host string) error {
+	if err == nil {
+		return nil
+	}
+	var connectErr *onepassword.Error
+	if errors.As(err, &connectErr) {
+		switch {
+		case connectErr.StatusCode == http.StatusNotFound:
+			return clienterrors.Wrap(clienterrors.ErrNotFound, err)
+		case connectErr.StatusCode == http.StatusUnauthorized || connectErr.StatusCode == http.StatusForbidden:
+			return clienterrors.Wrap(clienterrors.ErrUnauthorized, err)
+		case connectErr.StatusCode == http.StatusTooManyRequests:
+			return clienterrors.WrapRateLimited(err, hints.RetryAfter(host))
+		case connectErr.StatusCode >= http.StatusInternalServerError:
+			return clienterrors.Wrap(clienterrors.ErrUnavailable, err)
+		}
+		return err
+	}
+
+	// Connect could not be reached at all.
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return clienterrors.Wrap(clienterrors.ErrUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,93 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/testing/mock"
+)
+
+func TestWrapError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected error
+	}{
+		"not found": {
+			err:      &onepassword.Error{StatusCode: 404, Message: "Item not found"},
+			expected: clienterrors.ErrNotFound,
+		},
+		"unauthorized": {
+			err:      &onepassword.Error{StatusCode: 401, Message: "Invalid token signature"},
+			expected: clienterrors.ErrUnauthorized,
+		},
+		"forbidden": {
+			err:      &onepassword.Error{StatusCode: 403, Message: "Authorization failed"},
+			expected: clienterrors.ErrUnauthorized,
+		},
+		"rate limited": {
+			err:      &onepassword.Error{StatusCode: 429, Message: "Too Many Requests"},
+			expected: clienterrors.ErrRateLimited,
+		},
+		"server error": {
+			err:      &onepassword.Error{StatusCode: 503, Message: "Service Unavailable"},
+			expected: clienterrors.ErrUnavailable,
+		},
+		"connection refused": {
+			err: &url.Error{Op: "Get", URL: "http://onepassword-connect:8080", Err: &net.OpError{
+				Op: "dial", Net: "tcp", Err: errors.New("connection refused"),
+			}},
+			expected: clienterrors.ErrUnavailable,
+		},
+		"bad request": {
+			err: &onepassword.Error{StatusCode: 400, Message: "Invalid request"},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			err := wrapError(tc.err, nil, "")
+			require.ErrorIs(t, err, tc.err)
+			if tc.expected == nil {
+				require.Equal(t, "unknown", clienterrors.Reason(err))
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestConnect_GetItemByIDNotFound(t *testing.T) {
+	mockConnectClient := &mock.ConnectClientMock{}
+	mockConnectClient.On("GetItemByUUID", "item-id", "vault-id").Return((*onepassword.Item)(nil),
+		&onepassword.Error{StatusCode: 404, Message: "Item not found"})
+
+	client := &Connect{client: mockConnectClient}
+	_, err := client.GetItemByID(context.Background(), "vault-id", "item-id")
+	require.ErrorIs(t, err, clienterrors.ErrNotFound)
+}
+
+func TestConnect_RateLimitedRetryAfter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"status":429,"message":"Too Many Requests"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{ConnectHost: server.URL, ConnectToken: "token"})
+	_, err := client.ListItems(context.Background(), "abcdefghijklmnopqrstuvwxyz")
+	require.ErrorIs(t, err, clienterrors.ErrRateLimited)
+	retryAfter, ok := clienterrors.RetryAfter(err)
+	require.True(t, ok)
+	require.InDelta(t, 2*time.Minute, retryAfter, float64(5*time.Second))
+}
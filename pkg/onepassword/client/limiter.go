@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+// rateLimitedClient waits for the limiter before every request to the wrapped client.
+type rateLimitedClient struct {
+	client  Client
+	limiter *ratelimit.Limiter
+}
+
+func (c *rateLimitedClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.client.GetItemByID(ctx, vaultID, itemID)
+}
+
+func (c *rateLimitedClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+}
+
+func (c *rateLimitedClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.client.ListItems(ctx, vaultID)
+}
+
+func (c *rateLimitedClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+}
+
+func (c *rateLimitedClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	if err := c.wait(ctx); err != nil {
+		return nil, err
+	}
+	return c.client.GetVaultsByTitle(ctx, title)
+}
+
+func (c *rateLimitedClient) wait(ctx context.Context) error {
+	if err := c.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for the client-side rate limit: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/1Password/onepassword-operator/pkg/logs"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+// loggingClient logs the requests made with the wrapped client at debug level.
+type loggingClient struct {
+	client Client
+	logger logr.Logger
+}
+
+func (c *loggingClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	start := time.Now()
+	item, err := c.client.GetItemByID(ctx, vaultID, itemID)
+	c.log("GetItemByID", start, err, "vaultID", vaultID, "itemID", itemID)
+	return item, err
+}
+
+func (c *loggingClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	start := time.Now()
+	items, err := c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+	c.log("GetItemsByTitle", start, err, "vaultID", vaultID, "itemTitle", itemTitle)
+	return items, err
+}
+
+func (c *loggingClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	start := time.Now()
+	items, err := c.client.ListItems(ctx, vaultID)
+	c.log("ListItems", start, err, "vaultID", vaultID)
+	return items, err
+}
+
+func (c *loggingClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	start := time.Now()
+	content, err := c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+	c.log("GetFileContent", start, err, "vaultID", vaultID, "itemID", itemID, "fileID", fileID)
+	return content, err
+}
+
+func (c *loggingClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	start := time.Now()
+	vaults, err := c.client.GetVaultsByTitle(ctx, title)
+	c.log("GetVaultsByTitle", start, err, "title", title)
+	return vaults, err
+}
+
+func (c *loggingClient) log(operation string, start time.Time, err error, keysAndValues ...any) {
+	logger := c.logger.V(logs.DebugLevel)
+	if !logger.Enabled() {
+		return
+	}
+	logger = logger.WithValues(keysAndValues...).WithValues("operation", operation, "duration", time.Since(start))
+	if err != nil {
+		logger.Info("1Password request failed", "error", err.Error())
+		return
+	}
+	logger.Info("1Password request succeeded")
+}
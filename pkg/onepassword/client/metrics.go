@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+// Backends labeling the request metrics.
+const (
+	BackendConnect = "connect"
+	BackendSDK     = "sdk"
+)
+
+const outcomeSuccess = "success"
+
+var (
+	clientErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_client_errors_total",
+		Help: "Number of failed requests to 1Password by operation and reason.",
+	}, []string{"operation", "reason"})
+	clientRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_client_requests_total",
+		Help: "Number of requests to 1Password by backend, operation and outcome.",
+	}, []string{"backend", "operation", "outcome"})
+	clientRequestDurationSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "onepassword_client_request_duration_seconds",
+		Help:    "Latency of requests to 1Password by backend, operation and outcome.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"backend", "operation", "outcome"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(clientErrorsTotal, clientRequestsTotal, clientRequestDurationSeconds)
+}
+
+// metricsClient counts the requests made with the wrapped client and measures their latency.
+// The outcome is "success" or the reason of the error, as in the errors metric.
+type metricsClient struct {
+	client  Client
+	backend string
+}
+
+func (c *metricsClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	start := time.Now()
+	item, err := c.client.GetItemByID(ctx, vaultID, itemID)
+	c.observe("GetItemByID", start, err)
+	return item, err
+}
+
+func (c *metricsClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	start := time.Now()
+	items, err := c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+	c.observe("GetItemsByTitle", start, err)
+	return items, err
+}
+
+func (c *metricsClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	start := time.Now()
+	items, err := c.client.ListItems(ctx, vaultID)
+	c.observe("ListItems", start, err)
+	return items, err
+}
+
+func (c *metricsClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	start := time.Now()
+	content, err := c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+	c.observe("GetFileContent", start, err)
+	return content, err
+}
+
+func (c *metricsClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	start := time.Now()
+	vaults, err := c.client.GetVaultsByTitle(ctx, title)
+	c.observe("GetVaultsByTitle", start, err)
+	return vaults, err
+}
+
+func (c *metricsClient) observe(operation string, start time.Time, err error) {
+	outcome := outcomeSuccess
+	if err != nil {
+		outcome = clienterrors.Reason(err)
+		clientErrorsTotal.WithLabelValues(operation, outcome).Inc()
+	}
+	clientRequestsTotal.WithLabelValues(c.backend, operation, outcome).Inc()
+	clientRequestDurationSeconds.WithLabelValues(c.backend, operation, outcome).Observe(time.Since(start).Seconds())
+}
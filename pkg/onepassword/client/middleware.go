@@ -0,0 +1,75 @@
+package client
+
+import (
+	"math"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+)
+
+// Middleware wraps a Client to add behavior around the requests made with it,
+// such as logging, metrics, retries, caching or rate limiting.
+type Middleware func(next Client) Client
+
+// Chain wraps backend with middlewares. The first middleware is the outermost one:
+// it sees a request first and its response last. Nil middlewares are skipped.
+func Chain(backend Client, middlewares ...Middleware) Client {
+	client := backend
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		if middlewares[i] != nil {
+			client = middlewares[i](client)
+		}
+	}
+	return client
+}
+
+// Logging logs every request at debug level with its duration and error.
+func Logging(logger logr.Logger) Middleware {
+	return func(next Client) Client {
+		return &loggingClient{client: next, logger: logger}
+	}
+}
+
+// Metrics counts every request and measures its latency, labeled with backend, the operation and the outcome.
+func Metrics(backend string) Middleware {
+	return func(next Client) Client {
+		return &metricsClient{client: next, backend: backend}
+	}
+}
+
+// Tracing starts a span for every request, labeled with backend.
+func Tracing(backend string) Middleware {
+	return func(next Client) Client {
+		return &tracingClient{client: next, backend: backend}
+	}
+}
+
+// Retry retries the requests failing with a transient error following policy.
+func Retry(policy retry.Policy) Middleware {
+	return func(next Client) Client {
+		return &retryClient{client: next, policy: policy}
+	}
+}
+
+// RateLimit makes every request wait for a token of a bucket configured with limit.
+// The bucket is shared by all the clients wrapped with the returned middleware.
+// It returns nil, which Chain skips, when limit is unlimited.
+func RateLimit(limit ratelimit.Limit) Middleware {
+	if math.IsInf(limit.RequestsPerSecond, 1) {
+		return nil
+	}
+	limiter := ratelimit.NewLimiter(limit)
+	return func(next Client) Client {
+		return &rateLimitedClient{client: next, limiter: limiter}
+	}
+}
+
+// TitleCache caches the vaults and items found by title for ttl.
+func TitleCache(ttl time.Duration) Middleware {
+	return func(next Client) Client {
+		return newTitleCacheClient(next, ttl)
+	}
+}
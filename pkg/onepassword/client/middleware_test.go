@@ -0,0 +1,201 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing/tracingtest"
+)
+
+// recordingClient records its name in calls before passing GetVaultsByTitle to the embedded client.
+type recordingClient struct {
+	Client
+	name  string
+	calls *[]string
+}
+
+func (c *recordingClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	*c.calls = append(*c.calls, c.name)
+	return c.Client.GetVaultsByTitle(ctx, title)
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Client) Client {
+			return &recordingClient{Client: next, name: name, calls: &calls}
+		}
+	}
+
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetVaultsByTitle", "Employee").Return([]model.Vault{{ID: "vault-id"}}, nil)
+
+	c := Chain(mockClient, record("outer"), nil, record("inner"))
+	vaults, err := c.GetVaultsByTitle(context.Background(), "Employee")
+	require.NoError(t, err)
+	require.Equal(t, []model.Vault{{ID: "vault-id"}}, vaults)
+	require.Equal(t, []string{"outer", "inner"}, calls)
+
+	require.Same(t, mockClient, Chain(mockClient))
+}
+
+func TestRetry(t *testing.T) {
+	testCases := map[string]struct {
+		setup         func(mockClient *mocks.TestClient)
+		expectedErr   error
+		expectedCalls int
+	}{
+		"transient error": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetFileContent", "vault-id", "item-id", "file-id").
+					Return(nil, fmt.Errorf("file not synchronized: %w", ErrUnavailable)).Once()
+				mockClient.On("GetFileContent", "vault-id", "item-id", "file-id").Return([]byte("content"), nil)
+			},
+			expectedCalls: 2,
+		},
+		"attempts used up": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetFileContent", "vault-id", "item-id", "file-id").
+					Return(nil, fmt.Errorf("file not synchronized: %w", ErrUnavailable))
+			},
+			expectedErr:   ErrUnavailable,
+			expectedCalls: 3,
+		},
+		"permanent error": {
+			setup: func(mockClient *mocks.TestClient) {
+				mockClient.On("GetFileContent", "vault-id", "item-id", "file-id").
+					Return(nil, fmt.Errorf("file deleted: %w", ErrNotFound))
+			},
+			expectedErr:   ErrNotFound,
+			expectedCalls: 1,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			mockClient := &mocks.TestClient{}
+			tc.setup(mockClient)
+
+			c := Chain(mockClient, Retry(retry.Policy{
+				InitialInterval: time.Millisecond,
+				MaxInterval:     time.Millisecond,
+				MaxAttempts:     3,
+			}))
+			content, err := c.GetFileContent(context.Background(), "vault-id", "item-id", "file-id")
+			if tc.expectedErr != nil {
+				require.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, []byte("content"), content)
+			}
+			mockClient.AssertNumberOfCalls(t, "GetFileContent", tc.expectedCalls)
+		})
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	require.Nil(t, RateLimit(ratelimit.Unlimited))
+
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetVaultsByTitle", "Employee").Return([]model.Vault{}, nil)
+
+	// Clients wrapped with the same middleware share the bucket.
+	middleware := RateLimit(ratelimit.Limit{RequestsPerSecond: 0.001, Burst: 1})
+	first, second := Chain(mockClient, middleware), Chain(mockClient, middleware)
+
+	_, err := first.GetVaultsByTitle(context.Background(), "Employee")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = second.GetVaultsByTitle(ctx, "Employee")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	mockClient.AssertNumberOfCalls(t, "GetVaultsByTitle", 1)
+}
+
+func TestMetrics(t *testing.T) {
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetItemByID", "vault-id", "item-id").Return(nil, fmt.Errorf("item deleted: %w", ErrNotFound)).Once()
+	mockClient.On("GetItemByID", "vault-id", "item-id").Return(&model.Item{ID: "item-id"}, nil)
+
+	failures := clientErrorsTotal.WithLabelValues("GetItemByID", "not_found")
+	notFound := clientRequestsTotal.WithLabelValues(BackendConnect, "GetItemByID", "not_found")
+	success := clientRequestsTotal.WithLabelValues(BackendConnect, "GetItemByID", "success")
+	failuresBefore, notFoundBefore, successBefore := testutil.ToFloat64(failures), testutil.ToFloat64(notFound), testutil.ToFloat64(success)
+
+	c := Chain(mockClient, Metrics(BackendConnect))
+	_, err := c.GetItemByID(context.Background(), "vault-id", "item-id")
+	require.ErrorIs(t, err, ErrNotFound)
+	_, err = c.GetItemByID(context.Background(), "vault-id", "item-id")
+	require.NoError(t, err)
+
+	require.Equal(t, failuresBefore+1, testutil.ToFloat64(failures))
+	require.Equal(t, notFoundBefore+1, testutil.ToFloat64(notFound))
+	require.Equal(t, successBefore+1, testutil.ToFloat64(success))
+}
+
+func TestLogging(t *testing.T) {
+	var lines []string
+	logger := funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 1})
+
+	mockClient := &mocks.TestClient{}
+	mockClient.On("ListItems", "vault-id").Return([]model.Item{}, nil).Once()
+	mockClient.On("ListItems", "vault-id").Return([]model.Item{}, errors.New("connection refused"))
+
+	c := Chain(mockClient, Logging(logger))
+	_, err := c.ListItems(context.Background(), "vault-id")
+	require.NoError(t, err)
+	_, err = c.ListItems(context.Background(), "vault-id")
+	require.Error(t, err)
+
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"msg"="1Password request succeeded"`)
+	require.Contains(t, lines[0], `"operation"="ListItems"`)
+	require.Contains(t, lines[1], `"msg"="1Password request failed"`)
+	require.Contains(t, lines[1], `"error"="connection refused"`)
+
+	// Requests are not logged below the debug level.
+	lines = nil
+	c = Chain(mockClient, Logging(funcr.New(func(prefix, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})))
+	_, _ = c.ListItems(context.Background(), "vault-id")
+	require.Empty(t, lines)
+}
+
+func TestTracing(t *testing.T) {
+	exporter := tracingtest.Install(t)
+
+	mockClient := &mocks.TestClient{}
+	mockClient.On("GetItemsByTitle", "vault-id", "Database").Return([]model.Item{{ID: "item-id"}}, nil)
+	mockClient.On("GetItemByID", "vault-id", "item-id").Return(nil, fmt.Errorf("item deleted: %w", ErrNotFound))
+
+	c := Chain(mockClient, Tracing(BackendSDK))
+	_, err := c.GetItemsByTitle(context.Background(), "vault-id", "Database")
+	require.NoError(t, err)
+	_, err = c.GetItemByID(context.Background(), "vault-id", "item-id")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	spans := exporter.GetSpans()
+	require.Equal(t, []string{"Client.GetItemsByTitle", "Client.GetItemByID"}, tracingtest.SpanNames(exporter))
+	require.Contains(t, spans[0].Attributes, attribute.String("onepassword.backend", BackendSDK))
+	require.Contains(t, spans[0].Attributes, attribute.Int("onepassword.items", 1))
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Contains(t, spans[1].Attributes, attribute.String("onepassword.item_id", "item-id"))
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}
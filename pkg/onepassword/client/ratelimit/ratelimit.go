@@ -0,0 +1,205 @@
+// Package ratelimit implements the client-side token bucket limiting requests to 1Password,
+// serving requests that sync created or changed resources before background polling.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Priority decides which waiting request is served first when a token becomes available.
+type Priority int
+
+const (
+	// Interactive requests sync resources that were created or changed. This is the default.
+	Interactive Priority = iota
+	// Background requests poll 1Password for changes.
+	Background
+
+	priorities = 2
+)
+
+func (p Priority) String() string {
+	if p == Background {
+		return "background"
+	}
+	return "interactive"
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a context whose requests to 1Password are served with priority p.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFrom returns the priority set with WithPriority, Interactive if none is set.
+func PriorityFrom(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok && p >= 0 && p < priorities {
+		return p
+	}
+	return Interactive
+}
+
+var (
+	queuedCalls = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "onepassword_client_rate_limiter_queued_calls",
+		Help: "Number of requests to 1Password waiting for the client-side rate limiter, by priority.",
+	}, []string{"priority"})
+	throttledCallsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_client_rate_limiter_throttled_calls_total",
+		Help: "Number of requests to 1Password delayed by the client-side rate limiter, by priority.",
+	}, []string{"priority"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(queuedCalls, throttledCallsTotal)
+}
+
+// Limit configures a token bucket.
+type Limit struct {
+	// RequestsPerSecond is the rate the bucket is refilled at.
+	RequestsPerSecond float64
+	// Burst is the size of the bucket, the number of requests that can be made at once.
+	Burst int
+}
+
+// Unlimited lets every request through without waiting.
+var Unlimited = Limit{RequestsPerSecond: math.Inf(1)}
+
+// ParseLimit overrides the fields of defaults set in value, a comma separated list of
+// key=value pairs, e.g. "requestsPerSecond=2,burst=20". A rate of "inf" disables the limit.
+func ParseLimit(value string, defaults Limit) (Limit, error) {
+	limit := defaults
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rawValue, found := strings.Cut(entry, "=")
+		if !found {
+			return Limit{}, fmt.Errorf("invalid rate limit entry %q, must be key=value", entry)
+		}
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "requestsPerSecond":
+			limit.RequestsPerSecond, err = strconv.ParseFloat(rawValue, 64)
+			if err == nil && limit.RequestsPerSecond <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "burst":
+			limit.Burst, err = strconv.Atoi(rawValue)
+			if err == nil && limit.Burst < 1 {
+				err = fmt.Errorf("must be at least 1")
+			}
+		default:
+			return Limit{}, fmt.Errorf("unknown rate limit key %q", key)
+		}
+		if err != nil {
+			return Limit{}, fmt.Errorf("invalid value for rate limit key %q: %w", key, err)
+		}
+	}
+	return limit, nil
+}
+
+// Limiter is a token bucket with a queue per priority. Requests only queue once the bucket is
+// empty, and each token is handed to the oldest request of the highest priority waiting for it.
+type Limiter struct {
+	bucket *rate.Limiter
+
+	mu          sync.Mutex
+	lanes       [priorities][]chan struct{}
+	dispatching bool
+}
+
+// NewLimiter creates a limiter for limit.
+func NewLimiter(limit Limit) *Limiter {
+	return &Limiter{bucket: rate.NewLimiter(rate.Limit(limit.RequestsPerSecond), max(limit.Burst, 1))}
+}
+
+// Wait blocks until a request with the priority of ctx may be made, or ctx is done.
+func (l *Limiter) Wait(ctx context.Context) error {
+	p := PriorityFrom(ctx)
+
+	l.mu.Lock()
+	if l.queued() == 0 && l.bucket.Allow() {
+		l.mu.Unlock()
+		return nil
+	}
+	ready := make(chan struct{})
+	l.lanes[p] = append(l.lanes[p], ready)
+	queuedCalls.WithLabelValues(p.String()).Inc()
+	throttledCallsTotal.WithLabelValues(p.String()).Inc()
+	if !l.dispatching {
+		l.dispatching = true
+		go l.dispatch()
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		select {
+		case <-ready:
+			// The token was handed over in the meantime
+			return nil
+		default:
+		}
+		for i, waiting := range l.lanes[p] {
+			if waiting == ready {
+				l.lanes[p] = append(l.lanes[p][:i:i], l.lanes[p][i+1:]...)
+				break
+			}
+		}
+		queuedCalls.WithLabelValues(p.String()).Dec()
+		return ctx.Err()
+	}
+}
+
+// dispatch hands out tokens as the bucket refills, until no request is waiting.
+func (l *Limiter) dispatch() {
+	for {
+		l.mu.Lock()
+		if l.queued() == 0 {
+			l.dispatching = false
+			l.mu.Unlock()
+			return
+		}
+		l.mu.Unlock()
+
+		// The token is given to whoever waits with the highest priority once it is available,
+		// so requests arriving meanwhile go ahead of lower priority ones.
+		_ = l.bucket.Wait(context.Background())
+
+		l.mu.Lock()
+		for p := range l.lanes {
+			if len(l.lanes[p]) > 0 {
+				close(l.lanes[p][0])
+				l.lanes[p] = l.lanes[p][1:]
+				queuedCalls.WithLabelValues(Priority(p).String()).Dec()
+				break
+			}
+		}
+		l.mu.Unlock()
+	}
+}
+
+func (l *Limiter) queued() int {
+	n := 0
+	for _, lane := range l.lanes {
+		n += len(lane)
+	}
+	return n
+}
@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	defaults := Limit{RequestsPerSecond: 1, Burst: 20}
+	testCases := map[string]struct {
+		value    string
+		expected Limit
+		wantErr  bool
+	}{
+		"empty keeps defaults": {
+			value:    "",
+			expected: defaults,
+		},
+		"overrides set keys": {
+			value:    "requestsPerSecond=0.5, burst=5",
+			expected: Limit{RequestsPerSecond: 0.5, Burst: 5},
+		},
+		"unlimited": {
+			value:    "requestsPerSecond=inf",
+			expected: Limit{RequestsPerSecond: math.Inf(1), Burst: 20},
+		},
+		"zero rate": {
+			value:   "requestsPerSecond=0",
+			wantErr: true,
+		},
+		"zero burst": {
+			value:   "burst=0",
+			wantErr: true,
+		},
+		"unknown key": {
+			value:   "rate=1",
+			wantErr: true,
+		},
+		"missing value": {
+			value:   "burst",
+			wantErr: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			limit, err := ParseLimit(tc.value, defaults)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+func TestPriorityFrom(t *testing.T) {
+	require.Equal(t, Interactive, PriorityFrom(context.Background()))
+	require.Equal(t, Background, PriorityFrom(WithPriority(context.Background(), Background)))
+}
+
+func TestLimiterServesInteractiveFirst(t *testing.T) {
+	l := NewLimiter(Limit{RequestsPerSecond: 10, Burst: 1})
+	require.NoError(t, l.Wait(context.Background()), "The first request should use the burst")
+
+	served := make(chan Priority, 2)
+	wait := func(p Priority) {
+		if err := l.Wait(WithPriority(context.Background(), p)); err == nil {
+			served <- p
+		}
+	}
+	go wait(Background)
+	require.Eventually(t, func() bool { return queuedLen(l, Background) == 1 }, time.Second, time.Millisecond)
+	go wait(Interactive)
+	require.Eventually(t, func() bool { return queuedLen(l, Interactive) == 1 }, time.Second, time.Millisecond)
+
+	require.Equal(t, Interactive, <-served)
+	require.Equal(t, Background, <-served)
+}
+
+func TestLimiterWaitCancelled(t *testing.T) {
+	l := NewLimiter(Limit{RequestsPerSecond: 0.001, Burst: 1})
+	require.NoError(t, l.Wait(context.Background()))
+
+	queued := testutil.ToFloat64(queuedCalls.WithLabelValues(Background.String()))
+	throttled := testutil.ToFloat64(throttledCallsTotal.WithLabelValues(Background.String()))
+
+	ctx, cancel := context.WithTimeout(WithPriority(context.Background(), Background), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, l.Wait(ctx), context.DeadlineExceeded)
+
+	require.Equal(t, 0, queuedLen(l, Background))
+	require.Equal(t, queued, testutil.ToFloat64(queuedCalls.WithLabelValues(Background.String())))
+	require.Equal(t, throttled+1, testutil.ToFloat64(throttledCallsTotal.WithLabelValues(Background.String())))
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	l := NewLimiter(Unlimited)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, l.Wait(context.Background()))
+	}
+}
+
+func queuedLen(l *Limiter, p Priority) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.lanes[p])
+}
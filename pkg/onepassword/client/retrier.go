@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+// retryClient retries the requests to the wrapped client failing with a transient error.
+type retryClient struct {
+	client Client
+	policy retry.Policy
+}
+
+func (c *retryClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	return retry.Do(ctx, c.policy, func() (*model.Item, error) {
+		return c.client.GetItemByID(ctx, vaultID, itemID)
+	})
+}
+
+func (c *retryClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	return retry.Do(ctx, c.policy, func() ([]model.Item, error) {
+		return c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+	})
+}
+
+func (c *retryClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	return retry.Do(ctx, c.policy, func() ([]model.Item, error) {
+		return c.client.ListItems(ctx, vaultID)
+	})
+}
+
+func (c *retryClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	return retry.Do(ctx, c.policy, func() ([]byte, error) {
+		return c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+	})
+}
+
+func (c *retryClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	return retry.Do(ctx, c.policy, func() ([]model.Vault, error) {
+		return c.client.GetVaultsByTitle(ctx, title)
+	})
+}
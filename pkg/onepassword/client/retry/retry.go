@@ -0,0 +1,140 @@
+// Package retry implements the backoff policy used for requests to 1Password, both when
+// retrying a request in place and when deciding when to sync a secret again.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+)
+
+// Policy is an exponential backoff with jitter. Retry hints sent by 1Password, such as
+// Retry-After on rate limited requests, take precedence over the computed delay.
+type Policy struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the computed delay, jitter included. Do gives up instead of following a
+	// retry hint longer than this.
+	MaxInterval time.Duration
+	// Multiplier is applied to the delay after every attempt.
+	Multiplier float64
+	// Jitter randomizes the delay by up to this fraction in either direction, between 0 and 1.
+	Jitter float64
+	// MaxAttempts is the number of attempts made by Do, including the first. Zero or one disables retries.
+	MaxAttempts int
+}
+
+// DefaultPolicy returns the policy used to decide when to sync a secret again after a failure.
+func DefaultPolicy() Policy {
+	return Policy{
+		InitialInterval: 5 * time.Second,
+		MaxInterval:     15 * time.Minute,
+		Multiplier:      2,
+		Jitter:          0.2,
+	}
+}
+
+// Delay returns how long to wait before retrying after the given number of failed attempts,
+// the last of which failed with err. err may be nil.
+func (p Policy) Delay(failures int, err error) time.Duration {
+	if retryAfter, ok := clienterrors.RetryAfter(err); ok {
+		return retryAfter
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialInterval) * math.Pow(multiplier, float64(failures))
+	if p.Jitter > 0 {
+		delay *= 1 + p.Jitter*(2*rand.Float64()-1)
+	}
+	if p.MaxInterval > 0 && delay > float64(p.MaxInterval) {
+		delay = float64(p.MaxInterval)
+	}
+	// Without MaxInterval the delay keeps growing, and converting it past the range of a Duration is undefined.
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
+
+// Do calls fn until it succeeds or fails with an error that is not transient, waiting between
+// attempts as the policy says. It stops early when the attempts are used up, when the context is
+// done, or when 1Password asks to wait longer than MaxInterval, and then returns the last error.
+func Do[T any](ctx context.Context, p Policy, fn func() (T, error)) (T, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := fn()
+		if err == nil || !clienterrors.IsTransient(err) || attempt >= p.MaxAttempts {
+			return result, err
+		}
+
+		if retryAfter, ok := clienterrors.RetryAfter(err); ok && p.MaxInterval > 0 && retryAfter > p.MaxInterval {
+			return result, err
+		}
+		delay := p.Delay(attempt-1, err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return result, fmt.Errorf("%w (retry cancelled: %w)", err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// ParsePolicy overrides the fields of defaults set in value, a comma separated list of
+// key=value pairs, e.g. "initialInterval=1s,maxInterval=1m,multiplier=2,jitter=0.2,maxAttempts=5".
+func ParsePolicy(value string, defaults Policy) (Policy, error) {
+	policy := defaults
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, rawValue, found := strings.Cut(entry, "=")
+		if !found {
+			return Policy{}, fmt.Errorf("invalid retry policy entry %q, must be key=value", entry)
+		}
+
+		var err error
+		switch strings.TrimSpace(key) {
+		case "initialInterval":
+			policy.InitialInterval, err = time.ParseDuration(rawValue)
+		case "maxInterval":
+			policy.MaxInterval, err = time.ParseDuration(rawValue)
+		case "multiplier":
+			policy.Multiplier, err = strconv.ParseFloat(rawValue, 64)
+		case "jitter":
+			policy.Jitter, err = strconv.ParseFloat(rawValue, 64)
+		case "maxAttempts":
+			policy.MaxAttempts, err = strconv.Atoi(rawValue)
+		default:
+			return Policy{}, fmt.Errorf("unknown retry policy key %q", key)
+		}
+		if err != nil {
+			return Policy{}, fmt.Errorf("invalid value for retry policy key %q: %w", key, err)
+		}
+	}
+
+	if policy.InitialInterval <= 0 {
+		return Policy{}, fmt.Errorf("invalid retry policy initial interval %s, must be positive", policy.InitialInterval)
+	}
+	if policy.MaxInterval <= 0 {
+		return Policy{}, fmt.Errorf("invalid retry policy max interval %s, must be positive", policy.MaxInterval)
+	}
+	if policy.Multiplier < 1 {
+		return Policy{}, fmt.Errorf("invalid retry policy multiplier %v, must be at least 1", policy.Multiplier)
+	}
+	if policy.Jitter < 0 || policy.Jitter > 1 {
+		return Policy{}, fmt.Errorf("invalid retry policy jitter %v, must be between 0 and 1", policy.Jitter)
+	}
+	return policy, nil
+}
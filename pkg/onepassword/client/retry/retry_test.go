@@ -0,0 +1,192 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	p := Policy{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2}
+
+	require.Equal(t, time.Second, p.Delay(0, nil))
+	require.Equal(t, 2*time.Second, p.Delay(1, nil))
+	require.Equal(t, 8*time.Second, p.Delay(3, nil))
+	require.Equal(t, 10*time.Second, p.Delay(10, nil))
+
+	// Without MaxInterval, delays stop growing at the largest Duration instead of overflowing.
+	unbounded := Policy{InitialInterval: time.Second, Multiplier: 2}
+	require.Equal(t, time.Duration(math.MaxInt64), unbounded.Delay(100, nil))
+	require.Equal(t, time.Duration(math.MaxInt64), unbounded.Delay(10000, nil))
+
+	// Retry hints take precedence, even beyond MaxInterval.
+	rateLimited := &clienterrors.RateLimitError{RetryAfter: time.Minute, Err: errors.New("too many requests")}
+	require.Equal(t, time.Minute, p.Delay(0, rateLimited))
+}
+
+func TestPolicyDelayJitter(t *testing.T) {
+	p := Policy{InitialInterval: time.Second, MaxInterval: time.Minute, Multiplier: 2, Jitter: 0.5}
+
+	for i := 0; i < 100; i++ {
+		delay := p.Delay(2, nil)
+		require.GreaterOrEqual(t, delay, 2*time.Second)
+		require.LessOrEqual(t, delay, 6*time.Second)
+	}
+
+	// Jittered delays stay within MaxInterval.
+	for i := 0; i < 100; i++ {
+		require.LessOrEqual(t, p.Delay(10, nil), time.Minute)
+	}
+}
+
+func TestDo(t *testing.T) {
+	unavailable := clienterrors.Wrap(clienterrors.ErrUnavailable, errors.New("status 500"))
+	notFound := clienterrors.Wrap(clienterrors.ErrNotFound, errors.New("status 404"))
+	// Every delay is at MaxInterval, so jitter must not make Do give up.
+	p := Policy{
+		InitialInterval: 10 * time.Millisecond,
+		MaxInterval:     10 * time.Millisecond,
+		Multiplier:      2,
+		Jitter:          0.5,
+		MaxAttempts:     3,
+	}
+
+	testCases := map[string]struct {
+		errs             []error
+		expectedErr      error
+		expectedAttempts int
+	}{
+		"succeeds after transient errors": {
+			errs:             []error{unavailable, unavailable, nil},
+			expectedAttempts: 3,
+		},
+		"gives up after max attempts": {
+			errs:             []error{unavailable, unavailable, unavailable, nil},
+			expectedErr:      clienterrors.ErrUnavailable,
+			expectedAttempts: 3,
+		},
+		"does not retry other errors": {
+			errs:             []error{notFound, nil},
+			expectedErr:      clienterrors.ErrNotFound,
+			expectedAttempts: 1,
+		},
+		"does not wait longer than max interval": {
+			errs: []error{
+				&clienterrors.RateLimitError{RetryAfter: time.Hour, Err: errors.New("too many requests")},
+				nil,
+			},
+			expectedErr:      clienterrors.ErrRateLimited,
+			expectedAttempts: 1,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			attempts := 0
+			result, err := Do(context.Background(), p, func() (int, error) {
+				err := tc.errs[attempts]
+				attempts++
+				return attempts, err
+			})
+			require.Equal(t, tc.expectedAttempts, attempts)
+			require.Equal(t, tc.expectedAttempts, result)
+			if tc.expectedErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tc.expectedErr)
+		})
+	}
+}
+
+func TestDoCancelled(t *testing.T) {
+	unavailable := clienterrors.Wrap(clienterrors.ErrUnavailable, errors.New("status 500"))
+	p := Policy{InitialInterval: time.Hour, MaxAttempts: 3}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := Do(ctx, p, func() (struct{}, error) {
+		return struct{}{}, unavailable
+	})
+	require.ErrorIs(t, err, clienterrors.ErrUnavailable)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestParsePolicy(t *testing.T) {
+	defaults := DefaultPolicy()
+
+	testCases := map[string]struct {
+		value         string
+		expected      Policy
+		expectedError bool
+	}{
+		"empty": {
+			value:    "",
+			expected: defaults,
+		},
+		"overrides": {
+			value: "initialInterval=1s, maxInterval=1m,multiplier=3,jitter=0,maxAttempts=4",
+			expected: Policy{
+				InitialInterval: time.Second,
+				MaxInterval:     time.Minute,
+				Multiplier:      3,
+				Jitter:          0,
+				MaxAttempts:     4,
+			},
+		},
+		"unknown key": {
+			value:         "retries=3",
+			expectedError: true,
+		},
+		"invalid value": {
+			value:         "maxInterval=soon",
+			expectedError: true,
+		},
+		"missing value": {
+			value:         "maxAttempts",
+			expectedError: true,
+		},
+		"zero initial interval": {
+			value:         "initialInterval=0",
+			expectedError: true,
+		},
+		"zero max interval": {
+			value:         "maxInterval=0s",
+			expectedError: true,
+		},
+		"negative max interval": {
+			value:         "maxInterval=-1m",
+			expectedError: true,
+		},
+		"multiplier below one": {
+			value:         "multiplier=0.5",
+			expectedError: true,
+		},
+		"jitter above one": {
+			value:         "jitter=1.5",
+			expectedError: true,
+		},
+		"negative jitter": {
+			value:         "jitter=-0.1",
+			expectedError: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			policy, err := ParsePolicy(tc.value, defaults)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, policy)
+		})
+	}
+}
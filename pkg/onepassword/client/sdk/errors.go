@@ -0,0 +1,54 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	sdk "github.com/1password/onepassword-sdk-go"
+)
+
+// Apart from rate limiting, the SDK reports errors as plain messages. These are the
+// fragments of those messages that identify the kind of error.
+var (
+	notFoundMessages     = []string{"not found", "does not exist", "doesn't exist", "no vault", "no item"}
+	unauthorizedMessages = []string{"unauthorized", "unauthenticated", "forbidden", "permission", "invalid token"}
+	unavailableMessages  = []string{"timeout", "timed out", "unavailable", "connection", "internal server error", "bad gateway"}
+)
+
+// wrapError marks an error returned by the 1Password SDK with the matching clienterrors sentinel.
+// Rate limited errors carry the Retry-After hint of the last rate limited response that hints
+// recorded, as the SDK only reports a message.
+func wrapError(err error, hints *clienterrors.RetryAfterHints) error {
+	if err == nil {
+		return nil
+	}
+	var rateLimitErr *sdk.RateLimitExceededError
+	if errors.As(err, &rateLimitErr) {
+		return clienterrors.WrapRateLimited(err, hints.RetryAfter(""))
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return clienterrors.Wrap(clienterrors.ErrUnavailable, err)
+	}
+
+	message := strings.ToLower(err.Error())
+	switch {
+	case containsAny(message, notFoundMessages):
+		return clienterrors.Wrap(clienterrors.ErrNotFound, err)
+	case containsAny(message, unauthorizedMessages):
+		return clienterrors.Wrap(clienterrors.ErrUnauthorized, err)
+	case containsAny(message, unavailableMessages):
+		return clienterrors.Wrap(clienterrors.ErrUnavailable, err)
+	}
+	return err
+}
+
+func containsAny(message string, fragments []string) bool {
+	for _, fragment := range fragments {
+		if strings.Contains(message, fragment) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	sdk "github.com/1password/onepassword-sdk-go"
+)
+
+func TestWrapError(t *testing.T) {
+	testCases := map[string]struct {
+		err      error
+		expected error
+	}{
+		"rate limited": {
+			err:      fmt.Errorf("request failed: %w", &sdk.RateLimitExceededError{}),
+			expected: clienterrors.ErrRateLimited,
+		},
+		"deadline exceeded": {
+			err:      context.DeadlineExceeded,
+			expected: clienterrors.ErrUnavailable,
+		},
+		"item not found": {
+			err:      errors.New("error resolving item: item not found"),
+			expected: clienterrors.ErrNotFound,
+		},
+		"vault does not exist": {
+			err:      errors.New("the vault does not exist or is not accessible"),
+			expected: clienterrors.ErrNotFound,
+		},
+		"unauthorized": {
+			err:      errors.New("Unauthorized: invalid token"),
+			expected: clienterrors.ErrUnauthorized,
+		},
+		"connection error": {
+			err:      errors.New("error sending request: connection reset by peer"),
+			expected: clienterrors.ErrUnavailable,
+		},
+		"unknown": {
+			err: errors.New("invalid user input"),
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			err := wrapError(tc.err, nil)
+			require.ErrorIs(t, err, tc.err)
+			if tc.expected == nil {
+				require.Equal(t, "unknown", clienterrors.Reason(err))
+				return
+			}
+			require.ErrorIs(t, err, tc.expected)
+		})
+	}
+}
+
+func TestWrapErrorRetryAfter(t *testing.T) {
+	// The SDK sends its requests through the recording transport, and only reports the rate limit.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "90")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+	hints := clienterrors.NewRetryAfterHints(http.DefaultTransport)
+	resp, err := (&http.Client{Transport: hints}).Get(server.URL)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	err = wrapError(&sdk.RateLimitExceededError{}, hints)
+	require.ErrorIs(t, err, clienterrors.ErrRateLimited)
+	retryAfter, ok := clienterrors.RetryAfter(err)
+	require.True(t, ok)
+	require.InDelta(t, 90*time.Second, retryAfter, float64(5*time.Second))
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/clienterrors"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
 	sdk "github.com/1password/onepassword-sdk-go"
 )
@@ -16,12 +20,35 @@ type Config struct {
 	IntegrationVersion  string
 }
 
+// DefaultRateLimit returns the client-side rate limit of requests made with a service account.
+// It spreads the requests of all resources syncing at startup instead of exhausting the hourly limit at once.
+func DefaultRateLimit() ratelimit.Limit {
+	return ratelimit.Limit{RequestsPerSecond: 1, Burst: 20}
+}
+
+// DefaultRetryPolicy returns the retry policy of the SDK client. Rate limited requests are retried in
+// place a few times, after the Retry-After wait 1Password asks for when it is at most MaxInterval. As
+// service accounts are rate limited per hour, longer waits are left to the caller instead.
+func DefaultRetryPolicy() retry.Policy {
+	return retry.Policy{
+		InitialInterval: time.Second,
+		MaxInterval:     10 * time.Second,
+		Multiplier:      2,
+		Jitter:          0.2,
+		MaxAttempts:     3,
+	}
+}
+
 // SDK is a client for interacting with 1Password using the SDK.
 type SDK struct {
 	client *sdk.Client
+	// hints are the Retry-After hints of the responses of 1Password.
+	hints *clienterrors.RetryAfterHints
 }
 
 func NewClient(ctx context.Context, config Config) (*SDK, error) {
+	// Installed before the SDK makes its first request.
+	hints := clienterrors.DefaultRetryAfterHints()
 	client, err := sdk.NewClient(ctx,
 		sdk.WithServiceAccountToken(config.ServiceAccountToken),
 		sdk.WithIntegrationInfo(config.IntegrationName, config.IntegrationVersion),
@@ -32,13 +59,14 @@ func NewClient(ctx context.Context, config Config) (*SDK, error) {
 
 	return &SDK{
 		client: client,
+		hints:  hints,
 	}, nil
 }
 
 func (s *SDK) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
 	sdkItem, err := s.client.Items().Get(ctx, vaultID, itemID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password SDK: %w", err)
+		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password SDK: %w", wrapError(err, s.hints))
 	}
 
 	var item model.Item
@@ -50,7 +78,7 @@ func (s *SDK) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([
 	// Get all items in the vault
 	sdkItems, err := s.client.Items().List(ctx, vaultID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password SDK: %w", err)
+		return nil, fmt.Errorf("failed to GetItemsByTitle using 1Password SDK: %w", wrapError(err, s.hints))
 	}
 
 	// Filter items by title
@@ -66,12 +94,25 @@ func (s *SDK) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([
 	return items, nil
 }
 
+func (s *SDK) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	sdkItems, err := s.client.Items().List(ctx, vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to ListItems using 1Password SDK: %w", wrapError(err, s.hints))
+	}
+
+	items := make([]model.Item, len(sdkItems))
+	for i, sdkItem := range sdkItems {
+		items[i].FromSDKItemOverview(&sdkItem)
+	}
+	return items, nil
+}
+
 func (s *SDK) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
 	bytes, err := s.client.Items().Files().Read(ctx, vaultID, itemID, sdk.FileAttributes{
 		ID: fileID,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetFileContent using 1Password SDK: %w", err)
+		return nil, fmt.Errorf("failed to GetFileContent using 1Password SDK: %w", wrapError(err, s.hints))
 	}
 
 	return bytes, nil
@@ -81,7 +122,7 @@ func (s *SDK) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault
 	// List all vaults
 	sdkVaults, err := s.client.Vaults().List(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to GetVaultsByTitle using 1Password SDK: %w", err)
+		return nil, fmt.Errorf("failed to GetVaultsByTitle using 1Password SDK: %w", wrapError(err, s.hints))
 	}
 
 	// Filter vaults by title
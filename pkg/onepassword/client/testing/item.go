@@ -76,6 +76,7 @@ func CheckConnectItemMapping(t *testing.T, expected *onepassword.Item, actual *m
 	}
 
 	require.Equal(t, expected.CreatedAt, actual.CreatedAt)
+	require.Equal(t, expected.UpdatedAt, actual.UpdatedAt)
 }
 
 func CheckSDKItemMapping(t *testing.T, expected *sdk.Item, actual *model.Item) {
@@ -98,6 +99,7 @@ func CheckSDKItemMapping(t *testing.T, expected *sdk.Item, actual *model.Item) {
 	}
 
 	require.Equal(t, expected.CreatedAt, actual.CreatedAt)
+	require.Equal(t, expected.UpdatedAt, actual.UpdatedAt)
 }
 
 func CheckSDKItemOverviewMapping(t *testing.T, expected *sdk.ItemOverview, actual *model.Item) {
@@ -107,4 +109,5 @@ func CheckSDKItemOverviewMapping(t *testing.T, expected *sdk.ItemOverview, actua
 	require.Equal(t, expected.VaultID, actual.VaultID)
 	require.ElementsMatch(t, expected.Tags, actual.Tags)
 	require.Equal(t, expected.CreatedAt, actual.CreatedAt)
+	require.Equal(t, expected.UpdatedAt, actual.UpdatedAt)
 }
@@ -37,8 +37,8 @@ func (c *ConnectClientMock) GetVaultsByTitle(title string) ([]onepassword.Vault,
 }
 
 func (c *ConnectClientMock) GetItems(vaultQuery string) ([]onepassword.Item, error) {
-	// Only implement this if mocking is needed
-	panic("implement me")
+	args := c.Called(vaultQuery)
+	return args.Get(0).([]onepassword.Item), args.Error(1)
 }
 
 func (c *ConnectClientMock) GetItem(itemQuery, vaultQuery string) (*onepassword.Item, error) {
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
+)
+
+// tracingClient starts a span for every request made with the wrapped client.
+type tracingClient struct {
+	client  Client
+	backend string
+}
+
+func (c *tracingClient) GetItemByID(ctx context.Context, vaultID, itemID string) (*model.Item, error) {
+	ctx, span := c.start(ctx, "GetItemByID",
+		attribute.String("onepassword.vault_id", vaultID), attribute.String("onepassword.item_id", itemID))
+	item, err := c.client.GetItemByID(ctx, vaultID, itemID)
+	tracing.End(span, err)
+	return item, err
+}
+
+func (c *tracingClient) GetItemsByTitle(ctx context.Context, vaultID, itemTitle string) ([]model.Item, error) {
+	ctx, span := c.start(ctx, "GetItemsByTitle",
+		attribute.String("onepassword.vault_id", vaultID), attribute.String("onepassword.item", itemTitle))
+	items, err := c.client.GetItemsByTitle(ctx, vaultID, itemTitle)
+	span.SetAttributes(attribute.Int("onepassword.items", len(items)))
+	tracing.End(span, err)
+	return items, err
+}
+
+func (c *tracingClient) ListItems(ctx context.Context, vaultID string) ([]model.Item, error) {
+	ctx, span := c.start(ctx, "ListItems", attribute.String("onepassword.vault_id", vaultID))
+	items, err := c.client.ListItems(ctx, vaultID)
+	span.SetAttributes(attribute.Int("onepassword.items", len(items)))
+	tracing.End(span, err)
+	return items, err
+}
+
+func (c *tracingClient) GetFileContent(ctx context.Context, vaultID, itemID, fileID string) ([]byte, error) {
+	ctx, span := c.start(ctx, "GetFileContent",
+		attribute.String("onepassword.vault_id", vaultID), attribute.String("onepassword.item_id", itemID),
+		attribute.String("onepassword.file_id", fileID))
+	content, err := c.client.GetFileContent(ctx, vaultID, itemID, fileID)
+	tracing.End(span, err)
+	return content, err
+}
+
+func (c *tracingClient) GetVaultsByTitle(ctx context.Context, title string) ([]model.Vault, error) {
+	ctx, span := c.start(ctx, "GetVaultsByTitle", attribute.String("onepassword.vault", title))
+	vaults, err := c.client.GetVaultsByTitle(ctx, title)
+	span.SetAttributes(attribute.Int("onepassword.vaults", len(vaults)))
+	tracing.End(span, err)
+	return vaults, err
+}
+
+func (c *tracingClient) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracing.Start(ctx, "Client."+operation, append(attrs, attribute.String("onepassword.backend", c.backend))...)
+}
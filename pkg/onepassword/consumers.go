@@ -0,0 +1,111 @@
+package onepassword
+
+import (
+	"bytes"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// consumerKind is how the containers of a pod receive the data of a secret, which decides whether
+// they need a restart to see changes.
+type consumerKind string
+
+const (
+	// consumerEnv reads the secret into environment variables when the container starts.
+	consumerEnv consumerKind = "env"
+	// consumerSubPathMount mounts files of the secret with subPath, which the kubelet never updates.
+	// Mounts of init containers are counted as well, as init containers only run when the pod starts.
+	consumerSubPathMount consumerKind = "subPath"
+	// consumerLiveMount mounts the secret volume as a whole, which the kubelet updates when the secret changes.
+	consumerLiveMount consumerKind = "liveMount"
+)
+
+// secretConsumer is a use of a secret by the containers of a pod template.
+type secretConsumer struct {
+	kind consumerKind
+	// keys are the keys used through secretKeyRef or the items of a volume, nil when all keys are used.
+	keys []string
+}
+
+// secretUsage lists how the containers of a pod template use a secret.
+type secretUsage []secretConsumer
+
+// needsRestart reports whether any consumer reading one of the changed keys needs a restart to see the
+// change. A nil list of keys stands for all keys. Live mounts need a restart unless skipLiveMounts is set.
+func (u secretUsage) needsRestart(changedKeys []string, skipLiveMounts bool) bool {
+	for _, consumer := range u {
+		if consumer.kind == consumerLiveMount && skipLiveMounts {
+			continue
+		}
+		if consumer.keys == nil || changedKeys == nil ||
+			slices.ContainsFunc(changedKeys, func(key string) bool { return slices.Contains(consumer.keys, key) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSecretUsages returns how the containers and volumes of the pod template use each secret, by name.
+// It finds the secrets the same way as AppendUpdatedContainerSecrets and AppendUpdatedVolumeSecrets.
+func getSecretUsages(podTemplate *corev1.PodTemplateSpec) map[string]secretUsage {
+	usages := map[string]secretUsage{}
+	containers := slices.Concat(podTemplate.Spec.Containers, podTemplate.Spec.InitContainers)
+	containerSecretRefs(containers, func(name string, keys []string) {
+		usages[name] = append(usages[name], secretConsumer{kind: consumerEnv, keys: keys})
+	})
+	for _, volume := range podTemplate.Spec.Volumes {
+		kinds := volumeMountKinds(podTemplate, volume.Name)
+		volumeSecretRefs(volume, func(name string, keys []string) {
+			for _, kind := range kinds {
+				usages[name] = append(usages[name], secretConsumer{kind: kind, keys: keys})
+			}
+		})
+	}
+	return usages
+}
+
+// volumeMountKinds returns how the containers of the pod template mount the named volume. Volumes mounted
+// by no container are kept up to date by the kubelet all the same, and count as live mounts.
+func volumeMountKinds(podTemplate *corev1.PodTemplateSpec, volumeName string) []consumerKind {
+	var kinds []consumerKind
+	addMounts := func(containers []corev1.Container, init bool) {
+		for _, container := range containers {
+			for _, mount := range container.VolumeMounts {
+				if mount.Name != volumeName {
+					continue
+				}
+				kind := consumerLiveMount
+				if init || mount.SubPath != "" || mount.SubPathExpr != "" {
+					kind = consumerSubPathMount
+				}
+				if !slices.Contains(kinds, kind) {
+					kinds = append(kinds, kind)
+				}
+			}
+		}
+	}
+	addMounts(podTemplate.Spec.Containers, false)
+	addMounts(podTemplate.Spec.InitContainers, true)
+	if len(kinds) == 0 {
+		return []consumerKind{consumerLiveMount}
+	}
+	return kinds
+}
+
+// changedSecretKeys returns the sorted keys added, changed or removed between two versions of secret data.
+func changedSecretKeys(previous, current map[string][]byte) []string {
+	changed := []string{}
+	for key, value := range current {
+		if previousValue, found := previous[key]; !found || !bytes.Equal(previousValue, value) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range previous {
+		if _, found := current[key]; !found {
+			changed = append(changed, key)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
@@ -0,0 +1,143 @@
+package onepassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetSecretUsages(t *testing.T) {
+	podTemplate := &corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{{
+			Env: []corev1.EnvVar{{Name: "TOKEN", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "keys"}, Key: "token"},
+			}}},
+			VolumeMounts: []corev1.VolumeMount{{Name: "init", MountPath: "/init"}},
+		}},
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "keys"}, Key: "password"},
+			}}},
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "env-from"}}},
+			},
+			VolumeMounts: []corev1.VolumeMount{
+				{Name: "whole", MountPath: "/whole"},
+				{Name: "items", MountPath: "/etc/tls/tls.crt", SubPath: "cert"},
+				{Name: "projected", MountPath: "/projected"},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			{Name: "whole", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "volume"}}},
+			{Name: "items", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: "keys",
+				Items:      []corev1.KeyToPath{{Key: "tls.crt", Path: "cert"}},
+			}}},
+			{Name: "projected", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+				Sources: []corev1.VolumeProjection{
+					{Secret: &corev1.SecretProjection{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "projected"},
+						Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca"}},
+					}},
+					{Secret: &corev1.SecretProjection{LocalObjectReference: corev1.LocalObjectReference{Name: "projected-whole"}}},
+				},
+			}}},
+			{Name: "init", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "init"}}},
+			{Name: "unmounted", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "unmounted"}}},
+		},
+	}}
+
+	assert.Equal(t, map[string]secretUsage{
+		"keys": {
+			{kind: consumerEnv, keys: []string{"password"}},
+			{kind: consumerEnv, keys: []string{"token"}},
+			{kind: consumerSubPathMount, keys: []string{"tls.crt"}},
+		},
+		"env-from":        {{kind: consumerEnv}},
+		"volume":          {{kind: consumerLiveMount}},
+		"projected":       {{kind: consumerLiveMount, keys: []string{"ca.crt"}}},
+		"projected-whole": {{kind: consumerLiveMount}},
+		"init":            {{kind: consumerSubPathMount}},
+		"unmounted":       {{kind: consumerLiveMount}},
+	}, getSecretUsages(podTemplate))
+}
+
+func TestSecretUsageNeedsRestart(t *testing.T) {
+	usage := secretUsage{
+		{kind: consumerEnv, keys: []string{"password"}},
+		{kind: consumerSubPathMount, keys: []string{"tls.crt"}},
+		{kind: consumerLiveMount, keys: []string{"config.yaml"}},
+	}
+	testCases := map[string]struct {
+		usage          secretUsage
+		changedKeys    []string
+		skipLiveMounts bool
+		expected       bool
+	}{
+		"env key changed": {
+			usage:       usage,
+			changedKeys: []string{"password", "username"},
+			expected:    true,
+		},
+		"subPath key changed": {
+			usage:          usage,
+			changedKeys:    []string{"tls.crt"},
+			skipLiveMounts: true,
+			expected:       true,
+		},
+		"live mount key changed": {
+			usage:       usage,
+			changedKeys: []string{"config.yaml"},
+			expected:    true,
+		},
+		"live mount key changed when skipping live mounts": {
+			usage:          usage,
+			changedKeys:    []string{"config.yaml"},
+			skipLiveMounts: true,
+		},
+		"unused key changed": {
+			usage:       usage,
+			changedKeys: []string{"username"},
+		},
+		"no key changed": {
+			usage:       usage,
+			changedKeys: []string{},
+		},
+		"unknown changes": {
+			usage:    usage,
+			expected: true,
+		},
+		"whole secret used": {
+			usage:       secretUsage{{kind: consumerEnv}},
+			changedKeys: []string{},
+			expected:    true,
+		},
+		"unknown changes to a live mount when skipping live mounts": {
+			usage:          secretUsage{{kind: consumerLiveMount}},
+			skipLiveMounts: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.usage.needsRestart(tc.changedKeys, tc.skipLiveMounts))
+		})
+	}
+}
+
+func TestChangedSecretKeys(t *testing.T) {
+	previous := map[string][]byte{
+		"unchanged": []byte("value"),
+		"changed":   []byte("old"),
+		"removed":   []byte("value"),
+	}
+	current := map[string][]byte{
+		"unchanged": []byte("value"),
+		"changed":   []byte("new"),
+		"added":     []byte("value"),
+	}
+
+	assert.Equal(t, []string{"added", "changed", "removed"}, changedSecretKeys(previous, current))
+	assert.Equal(t, []string{}, changedSecretKeys(current, current))
+}
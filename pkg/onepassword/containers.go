@@ -33,25 +33,28 @@ func AppendUpdatedContainerSecrets(
 	secrets map[string]*corev1.Secret,
 	updatedDeploymentSecrets map[string]*corev1.Secret,
 ) map[string]*corev1.Secret {
-	for i := 0; i < len(containers); i++ {
-		envVariables := containers[i].Env
-		for j := 0; j < len(envVariables); j++ {
-			if envVariables[j].ValueFrom != nil && envVariables[j].ValueFrom.SecretKeyRef != nil {
-				secret, ok := secrets[envVariables[j].ValueFrom.SecretKeyRef.Name]
-				if ok {
-					updatedDeploymentSecrets[secret.Name] = secret
-				}
+	containerSecretRefs(containers, func(name string, _ []string) {
+		secret, ok := secrets[name]
+		if ok {
+			updatedDeploymentSecrets[secret.Name] = secret
+		}
+	})
+	return updatedDeploymentSecrets
+}
+
+// containerSecretRefs calls ref with the name of each secret the env vars of the containers reference,
+// and the keys they read, nil for envFrom, which reads all keys.
+func containerSecretRefs(containers []corev1.Container, ref func(name string, keys []string)) {
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+				ref(env.ValueFrom.SecretKeyRef.Name, []string{env.ValueFrom.SecretKeyRef.Key})
 			}
 		}
-		envFromVariables := containers[i].EnvFrom
-		for j := 0; j < len(envFromVariables); j++ {
-			if envFromVariables[j].SecretRef != nil {
-				secret, ok := secrets[envFromVariables[j].SecretRef.Name]
-				if ok {
-					updatedDeploymentSecrets[secret.Name] = secret
-				}
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.SecretRef != nil {
+				ref(envFrom.SecretRef.Name, nil)
 			}
 		}
 	}
-	return updatedDeploymentSecrets
 }
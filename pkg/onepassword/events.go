@@ -0,0 +1,59 @@
+package onepassword
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// Event reasons recorded on OnePasswordItems, annotated workloads, the secrets generated for them
+// and the operator pod.
+const (
+	// ReasonSynced is recorded when a secret is created or updated from its 1Password item.
+	ReasonSynced = "Synced"
+	// ReasonItemNotFound is recorded when the item path does not resolve to a 1Password item.
+	ReasonItemNotFound = "ItemNotFound"
+	// ReasonFieldSkipped is recorded for every item field left out of a secret.
+	ReasonFieldSkipped = "FieldSkipped"
+	// ReasonUpdateIgnoredByTag is recorded when an item update is not applied because of the ignore-secret tag.
+	ReasonUpdateIgnoredByTag = "UpdateIgnoredByTag"
+	// ReasonWorkloadRestarted is recorded on a workload restarted to pick up updated secrets.
+	ReasonWorkloadRestarted = "WorkloadRestarted"
+	// ReasonRestartFailed is recorded on a workload that could not be restarted to pick up updated secrets.
+	ReasonRestartFailed = "RestartFailed"
+	// ReasonRestartRequired is recorded on a workload with the notify restart strategy that uses updated secrets.
+	ReasonRestartRequired = "RestartRequired"
+	// ReasonRestartQueued is recorded on a workload waiting for other workloads to be restarted first.
+	ReasonRestartQueued = "RestartQueued"
+	// ReasonRestartDebounced is recorded on a workload whose restart is delayed to coalesce further updates.
+	ReasonRestartDebounced = "RestartDebounced"
+	// ReasonRestartCircuitOpen is recorded when restarts of a workload are paused because it was restarted too often.
+	ReasonRestartCircuitOpen = "RestartCircuitOpen"
+	// ReasonRestartCircuitClosed is recorded when restarts of a workload resume after its circuit breaker opened.
+	ReasonRestartCircuitClosed = "RestartCircuitClosed"
+	// ReasonRolloutCompleted is recorded once all pods of a restarted workload are updated and available.
+	ReasonRolloutCompleted = "RolloutCompleted"
+	// ReasonRolloutTimedOut is recorded when a restarted workload did not become healthy within the rollout timeout.
+	ReasonRolloutTimedOut = "RolloutTimedOut"
+	// ReasonSecretDrifted is recorded when the data of a secret was changed outside of the operator.
+	ReasonSecretDrifted = "SecretDrifted"
+	// ReasonSecretDeleted is recorded when a secret was deleted outside of the operator.
+	ReasonSecretDeleted = "SecretDeleted"
+	// ReasonPollFailed is recorded on the operator pod when a run checking secrets for updates failed.
+	ReasonPollFailed = "PollFailed"
+)
+
+// secretEventTargets returns the secret and references to the objects owning it,
+// so that events about a secret show up when describing either of them.
+func secretEventTargets(secret *corev1.Secret) []runtime.Object {
+	targets := []runtime.Object{secret}
+	for _, owner := range secret.OwnerReferences {
+		targets = append(targets, &corev1.ObjectReference{
+			APIVersion: owner.APIVersion,
+			Kind:       owner.Kind,
+			Name:       owner.Name,
+			Namespace:  secret.Namespace,
+			UID:        owner.UID,
+		})
+	}
+	return targets
+}
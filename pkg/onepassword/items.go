@@ -5,15 +5,35 @@ import (
 	"fmt"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
+	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
 	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
 )
 
 var logger = logf.Log.WithName("retrieve_item")
 
-func GetOnePasswordItemByPath(ctx context.Context, opClient opclient.Client, path string) (*model.Item, error) {
+// GetOnePasswordItemByPath returns the item at path, including the content of its files.
+func GetOnePasswordItemByPath(ctx context.Context, opClient opclient.Client, path string) (item *model.Item, err error) {
+	ctx, span := tracing.Start(ctx, "GetOnePasswordItemByPath", attribute.String("onepassword.item_path", path))
+	defer func() { tracing.End(span, err) }()
+
+	item, err = GetOnePasswordItemMetadataByPath(ctx, opClient, path)
+	if err != nil {
+		return nil, err
+	}
+	if err := LoadItemFiles(ctx, opClient, item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+// GetOnePasswordItemMetadataByPath returns the item at path without downloading its files,
+// which is enough to compare versions. Use LoadItemFiles before building secret data from it.
+func GetOnePasswordItemMetadataByPath(ctx context.Context, opClient opclient.Client, path string) (*model.Item, error) {
 	vaultNameOrID, itemNameOrID, err := ParseVaultAndItemFromPath(path)
 	if err != nil {
 		return nil, err
@@ -23,19 +43,16 @@ func GetOnePasswordItemByPath(ctx context.Context, opClient opclient.Client, pat
 		return nil, fmt.Errorf("failed to 'getVaultID' for vaultNameOrID='%s': %w", vaultNameOrID, err)
 	}
 
-	var item *model.Item
 	// If it looks like a UUID, try fetching by ID first
 	if IsValidClientUUID(itemNameOrID) {
-		item, err = opClient.GetItemByID(ctx, vaultID, itemNameOrID)
+		item, err := opClient.GetItemByID(ctx, vaultID, itemNameOrID)
 		if err == nil {
-			// Success, load files and return
-			err = loadItemFiles(ctx, opClient, vaultID, item)
-			if err != nil {
-				return nil, fmt.Errorf("failed to load item files for vaultID='%s' and itemNameOrID='%s': %w",
-					vaultID, itemNameOrID, err)
-			}
 			return item, nil
 		}
+		// Falling back would hit the same rate limit or outage
+		if opclient.IsTransient(err) {
+			return nil, fmt.Errorf("failed to get item by ID for vaultID='%s' and itemID='%s': %w", vaultID, itemNameOrID, err)
+		}
 		// If UUID lookup failed, fallback to title lookup
 	}
 
@@ -45,17 +62,48 @@ func GetOnePasswordItemByPath(ctx context.Context, opClient opclient.Client, pat
 		return nil, fmt.Errorf("failed to get item for vaultID='%s' and itemNameOrID='%s': %w", vaultID, itemNameOrID, err)
 	}
 
-	item, err = opClient.GetItemByID(ctx, vaultID, itemID)
+	item, err := opClient.GetItemByID(ctx, vaultID, itemID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get item by ID for vaultID='%s' and itemID='%s': %w", vaultID, itemID, err)
 	}
+	return item, nil
+}
 
-	err = loadItemFiles(ctx, opClient, vaultID, item)
+// CheckOnePasswordItemExists resolves the vault and item of path without fetching the item's
+// fields or files, returning an error if either cannot be found.
+func CheckOnePasswordItemExists(ctx context.Context, opClient opclient.Client, path string) error {
+	vaultNameOrID, itemNameOrID, err := ParseVaultAndItemFromPath(path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load item files for vaultID='%s' and itemID='%s': %w", vaultID, itemID, err)
+		return err
+	}
+	vaultID, err := getVaultID(ctx, opClient, vaultNameOrID)
+	if err != nil {
+		return fmt.Errorf("vault %q not found: %w", vaultNameOrID, err)
 	}
 
-	return item, nil
+	if IsValidClientUUID(itemNameOrID) {
+		if _, err = opClient.GetItemByID(ctx, vaultID, itemNameOrID); err == nil {
+			return nil
+		}
+	}
+	if _, err = getItemIDByTitle(ctx, opClient, vaultID, itemNameOrID); err != nil {
+		return fmt.Errorf("item %q not found in vault %q: %w", itemNameOrID, vaultNameOrID, err)
+	}
+	return nil
+}
+
+// GetKeystoreItems retrieves the additional items a keystore spec reads PEM content from.
+func GetKeystoreItems(ctx context.Context, opClient opclient.Client,
+	keystore *onepasswordv1.OnePasswordItemKeystore) ([]model.Item, error) {
+	items := make([]model.Item, 0, len(keystore.ItemPaths))
+	for _, path := range keystore.ItemPaths {
+		item, err := GetOnePasswordItemByPath(ctx, opClient, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve keystore item %q: %w", path, err)
+		}
+		items = append(items, *item)
+	}
+	return items, nil
 }
 
 func ParseVaultAndItemFromPath(path string) (string, string, error) {
@@ -69,7 +117,13 @@ func ParseVaultAndItemFromPath(path string) (string, string, error) {
 	)
 }
 
-func getVaultID(ctx context.Context, client opclient.Client, vaultNameOrID string) (string, error) {
+func getVaultID(ctx context.Context, client opclient.Client, vaultNameOrID string) (vaultID string, err error) {
+	ctx, span := tracing.Start(ctx, "getVaultID", attribute.String("onepassword.vault", vaultNameOrID))
+	defer func() {
+		span.SetAttributes(attribute.String("onepassword.vault_id", vaultID))
+		tracing.End(span, err)
+	}()
+
 	// First try to get vault by title
 	vaults, err := client.GetVaultsByTitle(ctx, vaultNameOrID)
 	if err == nil && len(vaults) > 0 {
@@ -98,17 +152,24 @@ func getVaultID(ctx context.Context, client opclient.Client, vaultNameOrID strin
 	if err != nil {
 		return "", fmt.Errorf("failed to get vault by title %q: %w", vaultNameOrID, err)
 	}
-	return "", fmt.Errorf("no vaults found with identifier %q", vaultNameOrID)
+	return "", fmt.Errorf("no vaults found with identifier %q: %w", vaultNameOrID, opclient.ErrNotFound)
 }
 
-func getItemIDByTitle(ctx context.Context, client opclient.Client, vaultId, itemNameOrID string) (string, error) {
+func getItemIDByTitle(ctx context.Context, client opclient.Client, vaultId, itemNameOrID string) (itemID string, err error) {
+	ctx, span := tracing.Start(ctx, "getItemIDByTitle",
+		attribute.String("onepassword.vault_id", vaultId), attribute.String("onepassword.item", itemNameOrID))
+	defer func() {
+		span.SetAttributes(attribute.String("onepassword.item_id", itemID))
+		tracing.End(span, err)
+	}()
+
 	items, err := client.GetItemsByTitle(ctx, vaultId, itemNameOrID)
 	if err != nil {
 		return "", fmt.Errorf("failed to GetItemsByTitle for vaultID='%s' and itemTitle='%s': %w", vaultId, itemNameOrID, err)
 	}
 
 	if len(items) == 0 {
-		return "", fmt.Errorf("no items found with identifier %q in vault %q", itemNameOrID, vaultId)
+		return "", fmt.Errorf("no items found with identifier %q in vault %q: %w", itemNameOrID, vaultId, opclient.ErrNotFound)
 	}
 
 	oldestItem := items[0]
@@ -127,12 +188,17 @@ func getItemIDByTitle(ctx context.Context, client opclient.Client, vaultId, item
 	return oldestItem.ID, nil
 }
 
-func loadItemFiles(ctx context.Context, client opclient.Client, vaultID string,
-	item *model.Item) error {
+// LoadItemFiles downloads the content of the files of item.
+func LoadItemFiles(ctx context.Context, client opclient.Client, item *model.Item) (err error) {
+	ctx, span := tracing.Start(ctx, "LoadItemFiles",
+		attribute.String("onepassword.vault_id", item.VaultID), attribute.String("onepassword.item_id", item.ID),
+		attribute.Int("onepassword.files", len(item.Files)))
+	defer func() { tracing.End(span, err) }()
+
 	for i, file := range item.Files {
-		content, err := client.GetFileContent(ctx, vaultID, item.ID, file.ID)
+		content, err := client.GetFileContent(ctx, item.VaultID, item.ID, file.ID)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to load item files for vaultID='%s' and itemID='%s': %w", item.VaultID, item.ID, err)
 		}
 		item.Files[i].SetContent(content)
 	}
@@ -0,0 +1,139 @@
+package onepassword
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const (
+	syncResultSuccess = "success"
+	syncResultFailure = "failure"
+)
+
+var (
+	secretSyncsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_secret_syncs_total",
+		Help: "Number of syncs of Kubernetes secrets from 1Password by namespace and result.",
+	}, []string{"namespace", "result"})
+	pollDurationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "onepassword_poll_duration_seconds",
+		Help:    "Duration of the runs checking all secrets for updates in 1Password and restarting their workloads.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	})
+	pollRunsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_poll_runs_total",
+		Help: "Number of runs checking all secrets for updates in 1Password by result.",
+	}, []string{"result"})
+	workloadRestartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_workload_restarts_total",
+		Help: "Number of workloads restarted to pick up updated secrets by kind and namespace.",
+	}, []string{"kind", "namespace"})
+	ignoredItemUpdatesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "onepassword_ignored_item_updates_total",
+		Help: "Number of item updates not applied to a secret because the item is tagged " + lockTag + ", by namespace.",
+	}, []string{"namespace"})
+
+	lastSyncs = newLastSyncCollector()
+)
+
+func init() {
+	metrics.Registry.MustRegister(secretSyncsTotal, pollDurationSeconds, pollRunsTotal, workloadRestartsTotal,
+		ignoredItemUpdatesTotal, lastSyncs)
+}
+
+// ObserveSecretSynced records that the secret namespace/name is up to date with item.
+func ObserveSecretSynced(namespace, name string, item *model.Item) {
+	secretSyncsTotal.WithLabelValues(namespace, syncResultSuccess).Inc()
+	lastSyncs.observe(types.NamespacedName{Namespace: namespace, Name: name},
+		fmt.Sprintf("vaults/%v/items/%v", item.VaultID, item.ID))
+}
+
+// ObserveSecretSyncFailed records that syncing a secret in namespace failed.
+func ObserveSecretSyncFailed(namespace string) {
+	secretSyncsTotal.WithLabelValues(namespace, syncResultFailure).Inc()
+}
+
+// observePollRun records the result of a run checking secrets for updates.
+func observePollRun(err error) {
+	result := syncResultSuccess
+	if err != nil {
+		result = syncResultFailure
+	}
+	pollRunsTotal.WithLabelValues(result).Inc()
+}
+
+// ForgetSecret stops reporting the last sync of the secret namespace/name once it is deleted.
+func ForgetSecret(namespace, name string) {
+	lastSyncs.forget(types.NamespacedName{Namespace: namespace, Name: name})
+}
+
+type lastSync struct {
+	item string
+	at   time.Time
+}
+
+// lastSyncCollector reports the seconds since each secret was last synced from its item.
+// The age is computed when scraped, so a stuck sync shows up without anything being recorded.
+type lastSyncCollector struct {
+	desc *prometheus.Desc
+	now  func() time.Time
+
+	mu    sync.Mutex
+	syncs map[types.NamespacedName]lastSync
+}
+
+func newLastSyncCollector() *lastSyncCollector {
+	return &lastSyncCollector{
+		desc: prometheus.NewDesc(
+			"onepassword_item_seconds_since_last_successful_sync",
+			"Seconds since the secret was last found up to date with its 1Password item.",
+			[]string{"namespace", "secret", "item"}, nil,
+		),
+		now:   time.Now,
+		syncs: map[types.NamespacedName]lastSync{},
+	}
+}
+
+func (c *lastSyncCollector) observe(secret types.NamespacedName, item string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.syncs[secret] = lastSync{item: item, at: c.now()}
+}
+
+func (c *lastSyncCollector) forget(secret types.NamespacedName) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.syncs, secret)
+}
+
+// retain forgets the secrets that are not in secrets, e.g. because they were deleted.
+func (c *lastSyncCollector) retain(secrets map[types.NamespacedName]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for secret := range c.syncs {
+		if !secrets[secret] {
+			delete(c.syncs, secret)
+		}
+	}
+}
+
+func (c *lastSyncCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *lastSyncCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for secret, last := range c.syncs {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(last.at).Seconds(),
+			secret.Namespace, secret.Name, last.item)
+	}
+}
@@ -0,0 +1,121 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+func TestUpdateSecretHandlerMetrics(t *testing.T) {
+	testCases := map[string]struct {
+		item            *model.Item
+		itemErr         error
+		expectedSynced  float64
+		expectedFailed  float64
+		expectedIgnored float64
+	}{
+		"up to date": {
+			item:           &model.Item{ID: itemId, VaultID: vaultId, Version: itemVersion},
+			expectedSynced: 1,
+		},
+		"updated": {
+			item:           &model.Item{ID: itemId, VaultID: vaultId, Version: itemVersion + 1},
+			expectedSynced: 1,
+		},
+		"ignored by tag": {
+			item:            &model.Item{ID: itemId, VaultID: vaultId, Version: itemVersion + 1, Tags: []string{lockTag}},
+			expectedSynced:  1,
+			expectedIgnored: 1,
+		},
+		"item not found": {
+			itemErr:        opclient.ErrNotFound,
+			expectedFailed: 1,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			secretNamespace := "metrics-" + strings.ReplaceAll(description, " ", "-")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "secret",
+					Namespace: secretNamespace,
+					Annotations: map[string]string{
+						VersionAnnotation:  fmt.Sprint(itemVersion),
+						ItemPathAnnotation: itemPath,
+					},
+				},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(secret).Build()
+
+			mockOpClient := &mocks.TestClient{}
+			mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(tc.item, tc.itemErr)
+			mockOpClient.On("GetItemsByTitle", mock.Anything, mock.Anything).Return([]model.Item{{ID: itemId}}, nil)
+			mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+
+			h := &SecretUpdateHandler{client: cl, apiReader: cl, opClient: mockOpClient}
+			require.NoError(t, h.UpdateKubernetesSecretsTask(context.Background()))
+
+			assert.Equal(t, tc.expectedSynced, testutil.ToFloat64(secretSyncsTotal.WithLabelValues(secretNamespace, syncResultSuccess)))
+			assert.Equal(t, tc.expectedFailed, testutil.ToFloat64(secretSyncsTotal.WithLabelValues(secretNamespace, syncResultFailure)))
+			assert.Equal(t, tc.expectedIgnored, testutil.ToFloat64(ignoredItemUpdatesTotal.WithLabelValues(secretNamespace)))
+
+			lastSyncs.mu.Lock()
+			_, reported := lastSyncs.syncs[types.NamespacedName{Namespace: secretNamespace, Name: "secret"}]
+			lastSyncs.mu.Unlock()
+			assert.Equal(t, tc.expectedSynced > 0, reported)
+		})
+	}
+}
+
+func TestRestartWorkloadCountsRestart(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "metrics-restart"}}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).Build()
+
+	h := &SecretUpdateHandler{client: cl, apiReader: cl}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "metrics-restart"}}
+	require.NoError(t, h.restartWorkload(context.Background(), deployment, secret))
+	assert.Equal(t, float64(1), testutil.ToFloat64(workloadRestartsTotal.WithLabelValues("Deployment", "metrics-restart")))
+}
+
+func TestLastSyncCollector(t *testing.T) {
+	now := time.Now()
+	c := newLastSyncCollector()
+	c.now = func() time.Time { return now }
+
+	first := types.NamespacedName{Namespace: "default", Name: "first"}
+	second := types.NamespacedName{Namespace: "default", Name: "second"}
+	c.observe(first, "vaults/vault-id/items/first-id")
+	c.observe(second, "vaults/vault-id/items/second-id")
+	now = now.Add(90 * time.Second)
+
+	expected := `
+# HELP onepassword_item_seconds_since_last_successful_sync Seconds since the secret was last found up to date with its 1Password item.
+# TYPE onepassword_item_seconds_since_last_successful_sync gauge
+onepassword_item_seconds_since_last_successful_sync{item="vaults/vault-id/items/first-id",namespace="default",secret="first"} 90
+onepassword_item_seconds_since_last_successful_sync{item="vaults/vault-id/items/second-id",namespace="default",secret="second"} 90
+`
+	require.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+
+	c.retain(map[types.NamespacedName]bool{first: true})
+	require.Equal(t, 1, testutil.CollectAndCount(c))
+	c.forget(first)
+	require.Equal(t, 0, testutil.CollectAndCount(c))
+}
@@ -12,11 +12,13 @@ type Item struct {
 	ID        string
 	VaultID   string
 	Version   int
+	Category  ItemCategory
 	Tags      []string
 	URLs      []ItemURL
 	Fields    []ItemField
 	Files     []File
 	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type ItemURL struct {
@@ -30,6 +32,7 @@ func (i *Item) FromConnectItem(item *connect.Item) {
 	i.ID = item.ID
 	i.VaultID = item.Vault.ID
 	i.Version = item.Version
+	i.Category = categoryFromConnect(item.Category)
 
 	i.Tags = append(i.Tags, item.Tags...)
 
@@ -43,6 +46,7 @@ func (i *Item) FromConnectItem(item *connect.Item) {
 
 	for _, field := range item.Fields {
 		i.Fields = append(i.Fields, ItemField{
+			ID:    field.ID,
 			Label: field.Label,
 			Value: field.Value,
 		})
@@ -57,6 +61,7 @@ func (i *Item) FromConnectItem(item *connect.Item) {
 	}
 
 	i.CreatedAt = item.CreatedAt
+	i.UpdatedAt = item.UpdatedAt
 }
 
 // FromSDKItem populates the Item from an SDK item.
@@ -64,6 +69,7 @@ func (i *Item) FromSDKItem(item *sdk.Item) {
 	i.ID = item.ID
 	i.VaultID = item.VaultID
 	i.Version = int(item.Version)
+	i.Category = categoryFromSDK(item.Category)
 
 	i.Tags = make([]string, len(item.Tags))
 	copy(i.Tags, item.Tags)
@@ -78,6 +84,7 @@ func (i *Item) FromSDKItem(item *sdk.Item) {
 
 	for _, field := range item.Fields {
 		i.Fields = append(i.Fields, ItemField{
+			ID:    field.ID,
 			Label: field.Title,
 			Value: field.Value,
 		})
@@ -101,15 +108,18 @@ func (i *Item) FromSDKItem(item *sdk.Item) {
 	}
 
 	i.CreatedAt = item.CreatedAt
+	i.UpdatedAt = item.UpdatedAt
 }
 
 // FromSDKItemOverview populates the Item from an SDK item overview.
 func (i *Item) FromSDKItemOverview(item *sdk.ItemOverview) {
 	i.ID = item.ID
 	i.VaultID = item.VaultID
+	i.Category = categoryFromSDK(item.Category)
 
 	i.Tags = make([]string, len(item.Tags))
 	copy(i.Tags, item.Tags)
 
 	i.CreatedAt = item.CreatedAt
+	i.UpdatedAt = item.UpdatedAt
 }
@@ -0,0 +1,57 @@
+package model
+
+import (
+	connect "github.com/1Password/connect-sdk-go/onepassword"
+	sdk "github.com/1password/onepassword-sdk-go"
+)
+
+// ItemCategory is the 1Password template an item was created from, independent of the backend.
+type ItemCategory string
+
+const (
+	ItemCategoryLogin         ItemCategory = "Login"
+	ItemCategoryPassword      ItemCategory = "Password"
+	ItemCategoryDatabase      ItemCategory = "Database"
+	ItemCategoryAPICredential ItemCategory = "APICredential"
+	ItemCategorySSHKey        ItemCategory = "SSHKey"
+	ItemCategoryDocument      ItemCategory = "Document"
+	ItemCategoryServer        ItemCategory = "Server"
+	ItemCategorySecureNote    ItemCategory = "SecureNote"
+	ItemCategoryOther         ItemCategory = "Other"
+)
+
+var connectCategories = map[connect.ItemCategory]ItemCategory{
+	connect.Login:         ItemCategoryLogin,
+	connect.Password:      ItemCategoryPassword,
+	connect.Database:      ItemCategoryDatabase,
+	connect.ApiCredential: ItemCategoryAPICredential,
+	connect.SSHKey:        ItemCategorySSHKey,
+	connect.Document:      ItemCategoryDocument,
+	connect.Server:        ItemCategoryServer,
+	connect.SecureNote:    ItemCategorySecureNote,
+}
+
+var sdkCategories = map[sdk.ItemCategory]ItemCategory{
+	sdk.ItemCategoryLogin:          ItemCategoryLogin,
+	sdk.ItemCategoryPassword:       ItemCategoryPassword,
+	sdk.ItemCategoryDatabase:       ItemCategoryDatabase,
+	sdk.ItemCategoryAPICredentials: ItemCategoryAPICredential,
+	sdk.ItemCategorySSHKey:         ItemCategorySSHKey,
+	sdk.ItemCategoryDocument:       ItemCategoryDocument,
+	sdk.ItemCategoryServer:         ItemCategoryServer,
+	sdk.ItemCategorySecureNote:     ItemCategorySecureNote,
+}
+
+func categoryFromConnect(category connect.ItemCategory) ItemCategory {
+	if c, ok := connectCategories[category]; ok {
+		return c
+	}
+	return ItemCategoryOther
+}
+
+func categoryFromSDK(category sdk.ItemCategory) ItemCategory {
+	if c, ok := sdkCategories[category]; ok {
+		return c
+	}
+	return ItemCategoryOther
+}
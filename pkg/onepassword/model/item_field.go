@@ -2,6 +2,7 @@ package model
 
 // ItemField Representation of a single field on an Item
 type ItemField struct {
+	ID    string
 	Label string
 	Value string
 }
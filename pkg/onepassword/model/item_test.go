@@ -16,11 +16,12 @@ func TestItem_FromConnectItem(t *testing.T) {
 		Vault: connect.ItemVault{
 			ID: "test-vault-id",
 		},
-		Version: 1,
-		Tags:    []string{"tag1", "tag2"},
+		Version:  1,
+		Category: connect.Database,
+		Tags:     []string{"tag1", "tag2"},
 		Fields: []*connect.ItemField{
-			{Label: "field1", Value: "value1"},
-			{Label: "field2", Value: "value2"},
+			{ID: "username", Label: "field1", Value: "value1"},
+			{ID: "password", Label: "field2", Value: "value2"},
 		},
 		Files: []*connect.File{
 			{ID: "file1", Name: "file1.txt", Size: 1234},
@@ -35,9 +36,11 @@ func TestItem_FromConnectItem(t *testing.T) {
 	require.Equal(t, connectItem.ID, item.ID)
 	require.Equal(t, connectItem.Vault.ID, item.VaultID)
 	require.Equal(t, connectItem.Version, item.Version)
+	require.Equal(t, ItemCategoryDatabase, item.Category)
 	require.ElementsMatch(t, connectItem.Tags, item.Tags)
 
 	for i, field := range connectItem.Fields {
+		require.Equal(t, field.ID, item.Fields[i].ID)
 		require.Equal(t, field.Label, item.Fields[i].Label)
 		require.Equal(t, field.Value, item.Fields[i].Value)
 	}
@@ -53,10 +56,11 @@ func TestItem_FromConnectItem(t *testing.T) {
 
 func TestItem_FromSDKItem(t *testing.T) {
 	sdkItem := &sdk.Item{
-		ID:      "test-item-id",
-		VaultID: "test-vault-id",
-		Version: 1,
-		Tags:    []string{"tag1", "tag2"},
+		ID:       "test-item-id",
+		VaultID:  "test-vault-id",
+		Version:  1,
+		Category: sdk.ItemCategoryAPICredentials,
+		Tags:     []string{"tag1", "tag2"},
 		Fields: []sdk.ItemField{
 			{ID: "1", Title: "field1", Value: "value1"},
 			{ID: "2", Title: "field2", Value: "value2"},
@@ -74,9 +78,11 @@ func TestItem_FromSDKItem(t *testing.T) {
 	require.Equal(t, sdkItem.ID, item.ID)
 	require.Equal(t, sdkItem.VaultID, item.VaultID)
 	require.Equal(t, int(sdkItem.Version), item.Version)
+	require.Equal(t, ItemCategoryAPICredential, item.Category)
 	require.ElementsMatch(t, sdkItem.Tags, item.Tags)
 
 	for i, field := range sdkItem.Fields {
+		require.Equal(t, field.ID, item.Fields[i].ID)
 		require.Equal(t, field.Title, item.Fields[i].Label)
 		require.Equal(t, field.Value, item.Fields[i].Value)
 	}
@@ -94,6 +100,7 @@ func TestItem_FromSDKItemOverview(t *testing.T) {
 	sdkItemOverview := &sdk.ItemOverview{
 		ID:        "test-item-id",
 		VaultID:   "test-vault-id",
+		Category:  sdk.ItemCategoryCryptoWallet,
 		Tags:      []string{"tag1", "tag2"},
 		CreatedAt: time.Now(),
 	}
@@ -103,6 +110,7 @@ func TestItem_FromSDKItemOverview(t *testing.T) {
 
 	require.Equal(t, sdkItemOverview.ID, item.ID)
 	require.Equal(t, sdkItemOverview.VaultID, item.VaultID)
+	require.Equal(t, ItemCategoryOther, item.Category)
 	require.ElementsMatch(t, sdkItemOverview.Tags, item.Tags)
 	require.Equal(t, sdkItemOverview.CreatedAt, item.CreatedAt)
 }
@@ -0,0 +1,324 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+)
+
+const (
+	defaultPollingInterval    = 10 * time.Minute
+	defaultPollingConcurrency = 5
+	defaultItemTimeout        = 30 * time.Second
+)
+
+var (
+	_ manager.Runnable               = &SecretUpdateHandler{}
+	_ manager.LeaderElectionRunnable = &SecretUpdateHandler{}
+)
+
+// pollerStatus tracks the runs of the secret poller, reported by the readiness check.
+type pollerStatus struct {
+	mu        sync.Mutex
+	started   time.Time
+	completed time.Time
+}
+
+// Start updates the kubernetes secrets every polling interval until ctx is cancelled, while a separate
+// worker restarts the workloads using updated secrets. It is called by the manager once this replica is
+// elected leader.
+func (h *SecretUpdateHandler) Start(ctx context.Context) error {
+	interval := h.pollingInterval()
+	h.status.mu.Lock()
+	h.status.started = time.Now()
+	h.status.mu.Unlock()
+
+	var restarts sync.WaitGroup
+	restarts.Add(1)
+	go func() {
+		defer restarts.Done()
+		h.runRestarts(ctx)
+	}()
+	defer restarts.Wait()
+
+	log.Info(fmt.Sprintf("Updating kubernetes secrets every %s", interval))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("Stopped updating kubernetes secrets")
+			return nil
+		case <-ticker.C:
+			err := h.UpdateKubernetesSecretsTask(ctx)
+			observePollRun(err)
+			if err != nil {
+				log.Error(err, "error running update kubernetes secret task")
+				h.recordPollFailed(err)
+			}
+
+			h.status.mu.Lock()
+			h.status.completed = time.Now()
+			h.status.mu.Unlock()
+		}
+	}
+}
+
+// NeedLeaderElection makes sure only one replica updates secrets and restarts workloads.
+func (h *SecretUpdateHandler) NeedLeaderElection() bool {
+	return true
+}
+
+// ReadyzCheck fails when no run completed for two polling intervals. It passes on replicas that are
+// not the leader, as they do not poll.
+//
+// Failed runs don't fail the check, as the webhooks served by the leader stop receiving requests once it
+// is not ready. They are reported by logs, the onepassword_poll_runs_total metric and PollFailed events.
+func (h *SecretUpdateHandler) ReadyzCheck(_ *http.Request) error {
+	h.status.mu.Lock()
+	defer h.status.mu.Unlock()
+
+	if h.status.started.IsZero() {
+		return nil
+	}
+
+	lastActivity := h.status.completed
+	if lastActivity.IsZero() {
+		lastActivity = h.status.started
+	}
+	if time.Since(lastActivity) > 2*h.pollingInterval() {
+		return fmt.Errorf("no update of kubernetes secrets completed since %s", lastActivity.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// recordPollFailed records a PollFailed event on the operator pod, if it is known.
+func (h *SecretUpdateHandler) recordPollFailed(err error) {
+	if h.config.OperatorPod == nil {
+		return
+	}
+	h.recordEvent(h.config.OperatorPod, corev1.EventTypeWarning, ReasonPollFailed,
+		fmt.Sprintf("Failed to update kubernetes secrets: %s", err))
+}
+
+func (h *SecretUpdateHandler) pollingInterval() time.Duration {
+	if h.config.PollingInterval > 0 {
+		return h.config.PollingInterval
+	}
+	return defaultPollingInterval
+}
+
+func (h *SecretUpdateHandler) pollingConcurrency() int {
+	if h.config.PollingConcurrency > 0 {
+		return h.config.PollingConcurrency
+	}
+	return defaultPollingConcurrency
+}
+
+func (h *SecretUpdateHandler) itemTimeout() time.Duration {
+	if h.config.ItemTimeout > 0 {
+		return h.config.ItemTimeout
+	}
+	return defaultItemTimeout
+}
+
+// itemFetcher fetches each item path once per run, however many secrets reference it.
+//
+// In batch mode each vault is listed once per run instead, and items are only fetched when
+// the listing shows they changed since they were fetched in a previous run.
+type itemFetcher struct {
+	opClient opclient.Client
+	timeout  time.Duration
+	batch    bool
+	// previous holds the items fetched in previous runs by vault and item ID.
+	previous map[string]knownItem
+
+	mu      sync.Mutex
+	fetches map[string]*itemFetch
+	vaults  map[string]*vaultListing
+	current map[string]knownItem
+}
+
+// knownItem is an item along with the version and update time its vault listing showed for it.
+type knownItem struct {
+	marker string
+	item   *model.Item
+}
+
+type vaultListing struct {
+	once  sync.Once
+	items map[string]model.Item
+	err   error
+}
+
+type itemFetch struct {
+	once sync.Once
+	item *model.Item
+	err  error
+
+	filesOnce     sync.Once
+	itemWithFiles *model.Item
+	filesErr      error
+}
+
+func newItemFetcher(opClient opclient.Client, timeout time.Duration) *itemFetcher {
+	return &itemFetcher{
+		opClient: opClient,
+		timeout:  timeout,
+		fetches:  map[string]*itemFetch{},
+	}
+}
+
+func newBatchItemFetcher(opClient opclient.Client, timeout time.Duration, previous map[string]knownItem) *itemFetcher {
+	f := newItemFetcher(opClient, timeout)
+	f.batch = true
+	f.previous = previous
+	f.vaults = map[string]*vaultListing{}
+	f.current = map[string]knownItem{}
+	return f
+}
+
+// get returns the item at path without the content of its files. The item is shared and must not be modified.
+func (f *itemFetcher) get(ctx context.Context, path string) (*model.Item, error) {
+	fetch := f.fetch(path)
+	fetch.once.Do(func() {
+		ctx, cancel := context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+		if f.batch {
+			fetch.item, fetch.err = f.getListed(ctx, path)
+		} else {
+			fetch.item, fetch.err = GetOnePasswordItemMetadataByPath(ctx, f.opClient, path)
+		}
+	})
+	return fetch.item, fetch.err
+}
+
+// getWithFiles returns the item at path including the content of its files, which are only
+// downloaded for secrets that need to be updated. The item is shared and must not be modified.
+func (f *itemFetcher) getWithFiles(ctx context.Context, path string) (*model.Item, error) {
+	item, err := f.get(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fetch := f.fetch(path)
+	fetch.filesOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+		// Copied as other secrets may be reading the item without files
+		itemWithFiles := *item
+		itemWithFiles.Files = slices.Clone(item.Files)
+		if fetch.filesErr = LoadItemFiles(ctx, f.opClient, &itemWithFiles); fetch.filesErr == nil {
+			fetch.itemWithFiles = &itemWithFiles
+		}
+	})
+	return fetch.itemWithFiles, fetch.filesErr
+}
+
+func (f *itemFetcher) fetch(path string) *itemFetch {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fetch, ok := f.fetches[path]
+	if !ok {
+		fetch = &itemFetch{}
+		f.fetches[path] = fetch
+	}
+	return fetch
+}
+
+// getListed returns the item at path without the content of its files, fetching it only when its
+// vault listing shows it changed since a previous run.
+func (f *itemFetcher) getListed(ctx context.Context, path string) (*model.Item, error) {
+	vaultNameOrID, itemNameOrID, err := ParseVaultAndItemFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+	vaultID, err := getVaultID(ctx, f.opClient, vaultNameOrID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to 'getVaultID' for vaultNameOrID='%s': %w", vaultNameOrID, err)
+	}
+	listedItems, err := f.listVault(ctx, vaultID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list items for vaultID='%s': %w", vaultID, err)
+	}
+
+	itemID := itemNameOrID
+	listedItem, listed := listedItems[itemID]
+	if !listed {
+		itemID, err = getItemIDByTitle(ctx, f.opClient, vaultID, itemNameOrID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get item for vaultID='%s' and itemNameOrID='%s': %w", vaultID, itemNameOrID, err)
+		}
+		listedItem, listed = listedItems[itemID]
+	}
+
+	key := vaultID + "/" + itemID
+	marker := fmt.Sprintf("%d@%s", listedItem.Version, listedItem.UpdatedAt.UTC().Format(time.RFC3339Nano))
+	if known, ok := f.previous[key]; ok && listed && known.marker == marker {
+		f.remember(key, known)
+		return known.item, nil
+	}
+
+	item, err := f.opClient.GetItemByID(ctx, vaultID, itemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item by ID for vaultID='%s' and itemID='%s': %w", vaultID, itemID, err)
+	}
+	if listed {
+		f.remember(key, knownItem{marker: marker, item: item})
+	}
+	return item, nil
+}
+
+// listVault lists the items of the vault once per run, by item ID.
+func (f *itemFetcher) listVault(ctx context.Context, vaultID string) (map[string]model.Item, error) {
+	f.mu.Lock()
+	listing, ok := f.vaults[vaultID]
+	if !ok {
+		listing = &vaultListing{}
+		f.vaults[vaultID] = listing
+	}
+	f.mu.Unlock()
+
+	listing.once.Do(func() {
+		items, err := f.opClient.ListItems(ctx, vaultID)
+		if err != nil {
+			listing.err = err
+			return
+		}
+		listing.items = make(map[string]model.Item, len(items))
+		for _, item := range items {
+			listing.items[item.ID] = item
+		}
+	})
+	return listing.items, listing.err
+}
+
+func (f *itemFetcher) remember(key string, known knownItem) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.current[key] = known
+}
+
+// known returns the items to compare the next run's vault listings against. Items no secret referenced
+// are dropped, unless the run did not complete and may not have reached them.
+func (f *itemFetcher) known(completed bool) map[string]knownItem {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if completed {
+		return f.current
+	}
+	known := make(map[string]knownItem, len(f.previous)+len(f.current))
+	maps.Copy(known, f.previous)
+	maps.Copy(known, f.current)
+	return known
+}
@@ -0,0 +1,129 @@
+package onepassword
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestSecretUpdateHandlerStart(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(defaultNamespace).Build()
+	h := NewSecretUpdateHandler(cl, cl, &mocks.TestClient{}, nil, SecretUpdateHandlerConfig{
+		PollingInterval: 10 * time.Millisecond,
+	})
+	require.True(t, h.NeedLeaderElection())
+	require.NoError(t, h.ReadyzCheck(nil), "Replicas that do not poll should be ready")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- h.Start(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		h.status.mu.Lock()
+		defer h.status.mu.Unlock()
+		return !h.status.completed.IsZero()
+	}, time.Second, 5*time.Millisecond)
+	assert.NoError(t, h.ReadyzCheck(nil))
+
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after the context was cancelled")
+	}
+}
+
+func TestSecretUpdateHandlerReadyzCheck(t *testing.T) {
+	testCases := map[string]struct {
+		started   time.Duration
+		completed time.Duration
+		ready     bool
+	}{
+		"not leader": {
+			ready: true,
+		},
+		"waiting for first run": {
+			started: time.Minute,
+			ready:   true,
+		},
+		"first run overdue": {
+			started: 30 * time.Minute,
+			ready:   false,
+		},
+		"last run succeeded": {
+			started:   time.Hour,
+			completed: time.Minute,
+			ready:     true,
+		},
+		"stalled": {
+			started:   time.Hour,
+			completed: 30 * time.Minute,
+			ready:     false,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			h := &SecretUpdateHandler{config: SecretUpdateHandlerConfig{PollingInterval: 10 * time.Minute}}
+			if tc.started > 0 {
+				h.status.started = time.Now().Add(-tc.started)
+			}
+			if tc.completed > 0 {
+				h.status.completed = time.Now().Add(-tc.completed)
+			}
+
+			err := h.ReadyzCheck(nil)
+			if tc.ready {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestSecretUpdateHandlerStartReportsFailedRuns(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(defaultNamespace).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+				return apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", errors.New("no RBAC"))
+			},
+		}).Build()
+	recorder := record.NewFakeRecorder(10)
+	h := NewSecretUpdateHandler(cl, cl, &mocks.TestClient{}, recorder, SecretUpdateHandlerConfig{
+		PollingInterval: 10 * time.Millisecond,
+		OperatorPod:     &corev1.ObjectReference{Kind: "Pod", Namespace: "onepassword", Name: "operator"},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = h.Start(ctx)
+	}()
+
+	select {
+	case event := <-recorder.Events:
+		assert.Contains(t, event, ReasonPollFailed)
+	case <-time.After(time.Second):
+		t.Fatal("No event recorded for the failed run")
+	}
+	assert.NoError(t, h.ReadyzCheck(nil), "Failed runs should not make the leader not ready")
+}
@@ -0,0 +1,258 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// circuitBreakerWindow is the period over which restarts count towards MaxRestartsPerHour.
+const circuitBreakerWindow = time.Hour
+
+// restartHistory tracks the restarts of each workload, to coalesce updates into fewer restarts and to
+// stop restarting workloads whose secrets keep changing. It is only kept in memory.
+type restartHistory struct {
+	// mu guards restarts, which are recorded by concurrent evictions.
+	mu sync.Mutex
+	// restarts are the times of the restarts of each workload within the circuit breaker window, oldest first.
+	restarts map[string][]time.Time
+	// pending are the restarts waiting for the debounce window to pass or the circuit breaker to close.
+	pending map[string]*debouncedRestart
+	// tripped are the workloads whose circuit breaker is open.
+	tripped map[string]bool
+}
+
+// debouncedRestart is a restart coalescing the updates seen since the first of them.
+type debouncedRestart struct {
+	pendingRestart
+	since time.Time
+}
+
+// restartsThrottled reports whether restarts go through the restart history before being started.
+func (h *SecretUpdateHandler) restartsThrottled() bool {
+	return h.config.RestartDebounceWindow > 0 || h.config.MaxRestartsPerHour > 0
+}
+
+// workloadKey identifies the workload in the restart history.
+func (h *SecretUpdateHandler) workloadKey(workload client.Object) string {
+	kind := fmt.Sprintf("%T", workload)
+	if gvk, err := apiutil.GVKForObject(workload, h.client.Scheme()); err == nil {
+		kind = gvk.GroupKind().String()
+	}
+	return kind + " " + client.ObjectKeyFromObject(workload).String()
+}
+
+// throttleRestarts adds the restarts to the pending restarts of their workloads, and returns the pending
+// restarts that are due: those whose first update is older than the debounce window, of workloads whose
+// circuit breaker is closed. The other restarts stay pending for a later run.
+func (h *SecretUpdateHandler) throttleRestarts(ctx context.Context, restarts []pendingRestart) []pendingRestart {
+	if !h.restartsThrottled() {
+		return restarts
+	}
+	history := &h.restartHistory
+	if history.pending == nil {
+		history.pending = map[string]*debouncedRestart{}
+	}
+	if history.tripped == nil {
+		history.tripped = map[string]bool{}
+	}
+
+	now := time.Now()
+	for _, restart := range restarts {
+		key := h.workloadKey(restart.workload)
+		pending, found := history.pending[key]
+		if !found {
+			history.pending[key] = &debouncedRestart{pendingRestart: restart, since: now}
+			if h.config.RestartDebounceWindow > 0 {
+				h.recordEvent(restart.workload, corev1.EventTypeNormal, ReasonRestartDebounced,
+					fmt.Sprintf("Restart to pick up changes to %s delayed by %s to coalesce further updates",
+						describeSecrets(restart.secrets), h.config.RestartDebounceWindow))
+			}
+			continue
+		}
+		pending.workload = restart.workload
+		pending.priority = restart.priority
+		pending.secrets = mergeSecrets(pending.secrets, restart.secrets)
+	}
+
+	for key, restarts := range history.restarts {
+		if restarts = recentRestarts(restarts, now); len(restarts) > 0 {
+			history.restarts[key] = restarts
+		} else {
+			delete(history.restarts, key)
+		}
+	}
+
+	var due []pendingRestart
+	for _, key := range slices.Sorted(maps.Keys(history.pending)) {
+		pending := history.pending[key]
+		if now.Sub(pending.since) < h.config.RestartDebounceWindow {
+			continue
+		}
+		needed, err := h.refreshPendingRestart(ctx, &pending.pendingRestart)
+		if err != nil {
+			log.Error(err, "Failed to refresh pending restart of workload",
+				"workload", pending.workload.GetName(), "namespace", pending.workload.GetNamespace())
+			continue
+		}
+		if !needed {
+			delete(history.pending, key)
+			continue
+		}
+		if h.isCircuitOpen(ctx, key, pending.workload, now) {
+			continue
+		}
+		delete(history.pending, key)
+		due = append(due, pending.pendingRestart)
+	}
+	return due
+}
+
+// refreshPendingRestart gets the latest state of the workload and secrets of a restart seen in an earlier
+// run, and reports whether the restart is still needed.
+func (h *SecretUpdateHandler) refreshPendingRestart(ctx context.Context, restart *pendingRestart) (bool, error) {
+	latest, ok := restart.workload.DeepCopyObject().(client.Object)
+	if !ok {
+		return false, fmt.Errorf("unexpected type %T", restart.workload)
+	}
+	if err := h.client.Get(ctx, client.ObjectKeyFromObject(restart.workload), latest); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	podTemplate, err := h.getPodTemplate(latest)
+	if err != nil {
+		return false, err
+	}
+
+	var secrets []*corev1.Secret
+	for _, secret := range restart.secrets {
+		current := &corev1.Secret{}
+		if err := h.client.Get(ctx, client.ObjectKeyFromObject(secret), current); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return false, err
+		}
+		// With checksums, secrets changed back to the data the workload runs with need no restart.
+		if h.isSecretChangedForPodTemplate(podTemplate, current) {
+			secrets = append(secrets, current)
+		}
+	}
+	restart.workload = latest
+	restart.secrets = secrets
+	return len(secrets) > 0, nil
+}
+
+// isCircuitOpen reports whether the circuit breaker of the workload is open, because it was restarted
+// MaxRestartsPerHour times within the last hour. The breaker trips with an annotation on the workload and
+// an event, and closes again once enough of these restarts are older than an hour, or once the annotation
+// is removed from the workload.
+func (h *SecretUpdateHandler) isCircuitOpen(ctx context.Context, key string, workload client.Object, now time.Time) bool {
+	if h.config.MaxRestartsPerHour <= 0 {
+		return false
+	}
+	history := &h.restartHistory
+	_, annotated := workload.GetAnnotations()[RestartCircuitOpenAnnotation]
+	if history.tripped[key] && !annotated {
+		log.Info(fmt.Sprintf("Circuit breaker of %T %q in namespace %q was reset by removing the %s annotation",
+			workload, workload.GetName(), workload.GetNamespace(), RestartCircuitOpenAnnotation))
+		delete(history.tripped, key)
+		delete(history.restarts, key)
+		return false
+	}
+
+	// The annotation outlives the history, e.g. when the operator restarted.
+	if annotated {
+		history.tripped[key] = true
+	}
+
+	restarts := history.restarts[key]
+	if len(restarts) < h.config.MaxRestartsPerHour {
+		if history.tripped[key] {
+			if err := h.setWorkloadAnnotation(ctx, workload, RestartCircuitOpenAnnotation, ""); err != nil {
+				log.Error(err, "Failed to remove the circuit breaker annotation of workload",
+					"workload", workload.GetName(), "namespace", workload.GetNamespace())
+			}
+			delete(history.tripped, key)
+			h.recordEvent(workload, corev1.EventTypeNormal, ReasonRestartCircuitClosed,
+				"Resuming restarts paused by the circuit breaker")
+		}
+		return false
+	}
+
+	if !history.tripped[key] {
+		if err := h.setWorkloadAnnotation(ctx, workload, RestartCircuitOpenAnnotation, now.Format(time.RFC3339)); err != nil {
+			log.Error(err, "Failed to set the circuit breaker annotation of workload",
+				"workload", workload.GetName(), "namespace", workload.GetNamespace())
+			return true
+		}
+		history.tripped[key] = true
+		// Restarts resume once the oldest of the last MaxRestartsPerHour restarts is an hour old.
+		resumeAt := restarts[len(restarts)-h.config.MaxRestartsPerHour].Add(circuitBreakerWindow)
+		h.recordEvent(workload, corev1.EventTypeWarning, ReasonRestartCircuitOpen,
+			fmt.Sprintf("Restarted %d times within the last hour, pausing restarts until %s",
+				len(restarts), resumeAt.Format(time.RFC3339)))
+	}
+	return true
+}
+
+// setWorkloadAnnotation sets an annotation of the workload, or removes it if value is empty.
+func (h *SecretUpdateHandler) setWorkloadAnnotation(ctx context.Context, workload client.Object, key, value string) error {
+	original, ok := workload.DeepCopyObject().(client.Object)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", workload)
+	}
+	annotations := workload.GetAnnotations()
+	if value == "" {
+		delete(annotations, key)
+	} else {
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[key] = value
+	}
+	workload.SetAnnotations(annotations)
+	return h.client.Patch(ctx, workload, client.MergeFrom(original))
+}
+
+// recordRestart adds a restart of the workload to its history when restarts are limited.
+func (h *SecretUpdateHandler) recordRestart(workload client.Object) {
+	if h.config.MaxRestartsPerHour <= 0 {
+		return
+	}
+	h.restartHistory.mu.Lock()
+	defer h.restartHistory.mu.Unlock()
+	if h.restartHistory.restarts == nil {
+		h.restartHistory.restarts = map[string][]time.Time{}
+	}
+	key := h.workloadKey(workload)
+	h.restartHistory.restarts[key] = append(h.restartHistory.restarts[key], time.Now())
+}
+
+// recentRestarts returns the restarts within the circuit breaker window before now.
+func recentRestarts(restarts []time.Time, now time.Time) []time.Time {
+	start := now.Add(-circuitBreakerWindow)
+	i, _ := slices.BinarySearchFunc(restarts, start, func(t, start time.Time) int { return t.Compare(start) })
+	return restarts[i:]
+}
+
+// mergeSecrets returns the secrets with the updated secrets replacing those of the same name, sorted by name.
+func mergeSecrets(secrets, updated []*corev1.Secret) []*corev1.Secret {
+	merged := slices.DeleteFunc(slices.Clone(secrets), func(secret *corev1.Secret) bool {
+		return slices.ContainsFunc(updated, func(u *corev1.Secret) bool { return u.Name == secret.Name })
+	})
+	merged = append(merged, updated...)
+	slices.SortFunc(merged, func(a, b *corev1.Secret) int { return strings.Compare(a.Name, b.Name) })
+	return merged
+}
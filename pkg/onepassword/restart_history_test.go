@@ -0,0 +1,180 @@
+package onepassword
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newRestartHistoryTestHandler(config SecretUpdateHandlerConfig) (*SecretUpdateHandler, client.Client, *record.FakeRecorder) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: generateContainersWithSecretRefsFromEnv([]string{"first", "second"})},
+		}},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		defaultNamespace,
+		deployment,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace}},
+	).Build()
+	recorder := record.NewFakeRecorder(20)
+	config.ShouldAutoRestartWorkloadsGlobally = true
+	return &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder, config: config}, cl, recorder
+}
+
+func updateTestSecret(t *testing.T, h *SecretUpdateHandler, cl client.Client, name string) {
+	t.Helper()
+	secret := &corev1.Secret{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, secret))
+	updated := map[string]map[string]*corev1.Secret{namespace: {name: secret}}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), updated, nil))
+	h.processRestarts(context.Background())
+}
+
+func getRestartHistoryTestDeployment(t *testing.T, cl client.Client) *appsv1.Deployment {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, deployment))
+	return deployment
+}
+
+func TestRestartWorkloadsDebounced(t *testing.T) {
+	h, cl, recorder := newRestartHistoryTestHandler(SecretUpdateHandlerConfig{RestartDebounceWindow: time.Minute})
+
+	updateTestSecret(t, h, cl, "first")
+	updateTestSecret(t, h, cl, "second")
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), nil, nil))
+	h.processRestarts(context.Background())
+	assert.False(t, isRestarted(t, cl, "web"))
+	assert.Equal(t, []string{
+		"Normal RestartDebounced Restart to pick up changes to Secret \"first\" delayed by 1m0s to coalesce further updates",
+	}, drainEvents(recorder))
+
+	for _, pending := range h.restartHistory.pending {
+		pending.since = pending.since.Add(-time.Minute)
+	}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), nil, nil))
+	h.processRestarts(context.Background())
+	assert.True(t, isRestarted(t, cl, "web"))
+	assert.Equal(t, []string{
+		"Normal WorkloadRestarted Restarted to pick up changes to Secrets \"first\", \"second\"",
+	}, drainEvents(recorder))
+	assert.Empty(t, h.restartHistory.pending)
+}
+
+func TestRestartWorkloadsCircuitBreaker(t *testing.T) {
+	h, cl, recorder := newRestartHistoryTestHandler(SecretUpdateHandlerConfig{MaxRestartsPerHour: 2})
+
+	updateTestSecret(t, h, cl, "first")
+	updateTestSecret(t, h, cl, "first")
+	assert.Len(t, drainEvents(recorder), 2)
+
+	updateTestSecret(t, h, cl, "second")
+	deployment := getRestartHistoryTestDeployment(t, cl)
+	assert.Contains(t, deployment.Annotations, RestartCircuitOpenAnnotation)
+	events := drainEvents(recorder)
+	require.Len(t, events, 1)
+	assert.Contains(t, events[0], "Warning RestartCircuitOpen Restarted 2 times within the last hour, pausing restarts until ")
+
+	// Updates keep being coalesced while the breaker is open.
+	updateTestSecret(t, h, cl, "first")
+	assert.Empty(t, drainEvents(recorder))
+
+	for key, restarts := range h.restartHistory.restarts {
+		h.restartHistory.restarts[key] = []time.Time{restarts[0].Add(-circuitBreakerWindow), restarts[1]}
+	}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), nil, nil))
+	h.processRestarts(context.Background())
+	deployment = getRestartHistoryTestDeployment(t, cl)
+	assert.NotContains(t, deployment.Annotations, RestartCircuitOpenAnnotation)
+	assert.Equal(t, []string{
+		"Normal RestartCircuitClosed Resuming restarts paused by the circuit breaker",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secrets \"first\", \"second\"",
+	}, drainEvents(recorder))
+	assert.Empty(t, h.restartHistory.pending)
+}
+
+func TestRestartWorkloadsCircuitBreakerReset(t *testing.T) {
+	h, cl, recorder := newRestartHistoryTestHandler(SecretUpdateHandlerConfig{MaxRestartsPerHour: 1})
+
+	updateTestSecret(t, h, cl, "first")
+	updateTestSecret(t, h, cl, "first")
+	assert.Len(t, drainEvents(recorder), 2)
+
+	deployment := getRestartHistoryTestDeployment(t, cl)
+	require.Contains(t, deployment.Annotations, RestartCircuitOpenAnnotation)
+	delete(deployment.Annotations, RestartCircuitOpenAnnotation)
+	require.NoError(t, cl.Update(context.Background(), deployment))
+
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), nil, nil))
+	h.processRestarts(context.Background())
+	assert.Equal(t, []string{
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"first\"",
+	}, drainEvents(recorder))
+	assert.Empty(t, h.restartHistory.pending)
+	assert.False(t, h.restartHistory.tripped[h.workloadKey(deployment)])
+}
+
+func TestRestartWorkloadsDebouncedWithChecksums(t *testing.T) {
+	h, cl, recorder := newRestartHistoryTestHandler(SecretUpdateHandlerConfig{
+		RestartDebounceWindow: time.Minute,
+		RestartMode:           RestartModeChecksum,
+	})
+	secret := &corev1.Secret{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "first"}, secret))
+	deployment := getRestartHistoryTestDeployment(t, cl)
+	deployment.Spec.Template.Annotations = map[string]string{SecretChecksumAnnotation("first"): secretChecksum(secret)}
+	require.NoError(t, cl.Update(context.Background(), deployment))
+
+	changed := secret.DeepCopy()
+	changed.Data = map[string][]byte{"password": []byte("new")}
+	updated := map[string]map[string]*corev1.Secret{namespace: {"first": changed}}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), updated, nil))
+	h.processRestarts(context.Background())
+	assert.Len(t, drainEvents(recorder), 1)
+
+	// The secret is changed back to the data the workload runs with before the restart is due.
+	for _, pending := range h.restartHistory.pending {
+		pending.since = pending.since.Add(-time.Minute)
+	}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(context.Background(), nil, nil))
+	h.processRestarts(context.Background())
+	assert.Empty(t, drainEvents(recorder))
+	assert.Empty(t, h.restartHistory.pending)
+}
+
+func TestRecentRestarts(t *testing.T) {
+	now := time.Now()
+	restarts := []time.Time{
+		now.Add(-2 * time.Hour),
+		now.Add(-time.Hour - time.Second),
+		now.Add(-time.Hour + time.Second),
+		now.Add(-time.Minute),
+	}
+
+	assert.Equal(t, restarts[2:], recentRestarts(restarts, now))
+	assert.Empty(t, recentRestarts(restarts, now.Add(time.Hour)))
+	assert.Empty(t, recentRestarts(nil, now))
+}
+
+func TestMergeSecrets(t *testing.T) {
+	first := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", ResourceVersion: "1"}}
+	second := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second"}}
+	updatedFirst := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", ResourceVersion: "2"}}
+	third := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "third"}}
+
+	assert.Equal(t, []*corev1.Secret{updatedFirst, second, third},
+		mergeSecrets([]*corev1.Secret{second, first}, []*corev1.Secret{third, updatedFirst}))
+}
@@ -0,0 +1,293 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+	"github.com/1Password/onepassword-operator/pkg/logs"
+)
+
+// RestartStrategy is how a workload is restarted, set with the restart-strategy annotation of the workload.
+type RestartStrategy string
+
+const (
+	// RestartStrategyRolling changes the pod template, so that the workload controller rolls out new pods.
+	RestartStrategyRolling RestartStrategy = "rolling"
+	// RestartStrategyEvict evicts the pods of the workload one at a time with the Eviction API, which
+	// honours PodDisruptionBudgets, waiting for each pod to be replaced before evicting the next one.
+	RestartStrategyEvict RestartStrategy = "evict"
+	// RestartStrategyCustomAnnotation sets the pod template annotation configured with CustomRestartAnnotation,
+	// for tools restarting workloads on their own terms.
+	RestartStrategyCustomAnnotation RestartStrategy = "annotation"
+	// RestartStrategyNotify leaves the workload running and reports that it needs a restart with an event
+	// and the restart required annotation.
+	RestartStrategyNotify RestartStrategy = "notify"
+)
+
+// restartStrategy returns the restart strategy of the workload, RestartStrategyRolling if unset or invalid.
+func restartStrategy(workload client.Object) RestartStrategy {
+	value, found := workload.GetAnnotations()[RestartStrategyAnnotation]
+	if !found {
+		return RestartStrategyRolling
+	}
+	switch strategy := RestartStrategy(value); strategy {
+	case RestartStrategyRolling, RestartStrategyEvict, RestartStrategyCustomAnnotation, RestartStrategyNotify:
+		return strategy
+	default:
+		log.Error(fmt.Errorf("unknown restart strategy %q", value), fmt.Sprintf(
+			"Error parsing %s annotation on %T %s. Must be %s, %s, %s or %s. Defaulting to %s.",
+			RestartStrategyAnnotation, workload, workload.GetName(), RestartStrategyRolling, RestartStrategyEvict,
+			RestartStrategyCustomAnnotation, RestartStrategyNotify, RestartStrategyRolling,
+		))
+		return RestartStrategyRolling
+	}
+}
+
+// updatePodTemplate restarts the workload by changing the annotations of its pod template.
+func (h *SecretUpdateHandler) updatePodTemplate(
+	ctx context.Context,
+	workload client.Object,
+	strategy RestartStrategy,
+	secrets []*corev1.Secret,
+) error {
+	var err error
+	if strategy == RestartStrategyCustomAnnotation {
+		err = h.setCustomRestartAnnotation(workload, secrets)
+	} else {
+		err = h.setRestartAnnotations(workload, secrets)
+	}
+	if err != nil {
+		log.Error(err, "Unsupported workload type for restart", "type", fmt.Sprintf("%T", workload))
+		return err
+	}
+
+	if err := h.client.Update(ctx, workload); err != nil {
+		log.Error(err, "Problem restarting workload", "name", workload.GetName())
+		return err
+	}
+	return nil
+}
+
+// setCustomRestartAnnotation sets the configured custom annotation of the pod template to the time of
+// the restart, or to a checksum of the secrets when restarting with checksums.
+func (h *SecretUpdateHandler) setCustomRestartAnnotation(workload client.Object, secrets []*corev1.Secret) error {
+	if h.config.CustomRestartAnnotation == "" {
+		return fmt.Errorf("no custom restart annotation is configured for the %s restart strategy", RestartStrategyCustomAnnotation)
+	}
+	value := time.Now().Format(time.RFC3339)
+	if h.config.RestartMode == RestartModeChecksum {
+		checksums := map[string][]byte{}
+		for _, secret := range secrets {
+			checksums[secret.Name] = []byte(secretChecksum(secret))
+		}
+		value = kubeSecrets.ContentHash(checksums)
+	}
+	return h.setPodTemplateAnnotation(workload, h.config.CustomRestartAnnotation, value)
+}
+
+// notifyRestartRequired reports that the workload uses updated secrets without restarting it.
+func (h *SecretUpdateHandler) notifyRestartRequired(ctx context.Context, workload client.Object, secrets []*corev1.Secret) error {
+	if err := h.setWorkloadAnnotation(ctx, workload, RestartRequiredAnnotation, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		log.Error(err, "Failed to set the restart required annotation of workload",
+			"workload", workload.GetName(), "namespace", workload.GetNamespace())
+		return err
+	}
+	h.recordEvent(workload, corev1.EventTypeNormal, ReasonRestartRequired,
+		fmt.Sprintf("Restart to pick up changes to %s", describeSecrets(secrets)))
+	return nil
+}
+
+// clearRestartRequired removes the restart required annotation of the workloads once they are rolled out
+// and all of their pods were created after it was set, so they run with the updated secrets.
+func (h *SecretUpdateHandler) clearRestartRequired(ctx context.Context) {
+	workloads, err := h.listWorkloads(ctx)
+	if err != nil {
+		log.Error(err, "Failed to list workloads to clear their restart required annotation")
+		return
+	}
+	for _, workload := range workloads {
+		since, required := restartRequiredSince(workload)
+		if !required || !isRolloutComplete(workload) {
+			continue
+		}
+		replaced, err := h.arePodsCreatedSince(ctx, workload, since)
+		if err != nil {
+			log.Error(err, "Failed to check the pods of workload",
+				"workload", workload.GetName(), "namespace", workload.GetNamespace())
+			continue
+		}
+		if !replaced {
+			continue
+		}
+		if err := h.setWorkloadAnnotation(ctx, workload, RestartRequiredAnnotation, ""); err != nil {
+			log.Error(err, "Failed to remove the restart required annotation of workload",
+				"workload", workload.GetName(), "namespace", workload.GetNamespace())
+		}
+	}
+}
+
+// restartRequiredSince returns when the workload was notified that it needs a restart, and whether it
+// still needs one.
+func restartRequiredSince(workload client.Object) (time.Time, bool) {
+	value, found := workload.GetAnnotations()[RestartRequiredAnnotation]
+	if !found {
+		return time.Time{}, false
+	}
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error parsing %s annotation on %T %s. Must be an RFC 3339 time.",
+			RestartRequiredAnnotation, workload, workload.GetName()))
+		return time.Time{}, false
+	}
+	return since, true
+}
+
+// arePodsCreatedSince reports whether all pods of the workload were created at or after since.
+func (h *SecretUpdateHandler) arePodsCreatedSince(ctx context.Context, workload client.Object, since time.Time) (bool, error) {
+	selector, err := workloadSelector(workload)
+	if err != nil {
+		return false, err
+	}
+	pods := &corev1.PodList{}
+	if err := h.apiReader.List(ctx, pods, client.InNamespace(workload.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return false, err
+	}
+	for _, pod := range pods.Items {
+		if pod.CreationTimestamp.Time.Before(since) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// evictsPods reports whether restarting the workload evicts its pods: with the evict strategy, and for
+// ReplicaSets, which don't replace their pods when their template changes.
+func evictsPods(workload client.Object) bool {
+	switch restartStrategy(workload) {
+	case RestartStrategyEvict:
+		return true
+	case RestartStrategyRolling:
+		_, ok := workload.(*appsv1.ReplicaSet)
+		return ok
+	default:
+		return false
+	}
+}
+
+// evictPods evicts the pods of the workload one at a time, waiting for each pod to be replaced and the
+// workload to be rolled out again before evicting the next one. It returns the number of evicted pods.
+func (h *SecretUpdateHandler) evictPods(ctx context.Context, workload client.Object) (int, error) {
+	selector, err := workloadSelector(workload)
+	if err != nil {
+		return 0, err
+	}
+	// Pods are read from the API server so that they are not all cached by the operator.
+	pods := &corev1.PodList{}
+	if err := h.apiReader.List(ctx, pods, client.InNamespace(workload.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return 0, err
+	}
+
+	evicted := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+		if err := h.evictPod(ctx, pod); err != nil {
+			return evicted, err
+		}
+		evicted++
+		if err := h.waitForPodReplacement(ctx, workload, pod); err != nil {
+			return evicted, err
+		}
+	}
+	return evicted, nil
+}
+
+// evictPod evicts the pod, retrying while a PodDisruptionBudget does not allow it for up to the rollout timeout.
+func (h *SecretUpdateHandler) evictPod(ctx context.Context, pod *corev1.Pod) error {
+	eviction := &policyv1.Eviction{ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace}}
+	err := wait.PollUntilContextTimeout(ctx, rolloutCheckInterval, h.rolloutTimeout(), true, func(ctx context.Context) (bool, error) {
+		err := h.client.SubResource("eviction").Create(ctx, pod, eviction)
+		switch {
+		case err == nil, apierrors.IsNotFound(err):
+			return true, nil
+		case apierrors.IsTooManyRequests(err):
+			log.V(logs.DebugLevel).Info(fmt.Sprintf("Eviction of pod %q in namespace %q is not allowed yet: %s", pod.Name, pod.Namespace, err))
+			return false, nil
+		default:
+			return false, err
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to evict pod %q: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// waitForPodReplacement waits until the evicted pod is gone and the workload is rolled out again.
+func (h *SecretUpdateHandler) waitForPodReplacement(ctx context.Context, workload client.Object, pod *corev1.Pod) error {
+	err := wait.PollUntilContextTimeout(ctx, rolloutCheckInterval, h.rolloutTimeout(), true, func(ctx context.Context) (bool, error) {
+		current := &corev1.Pod{}
+		err := h.apiReader.Get(ctx, client.ObjectKeyFromObject(pod), current)
+		if err == nil && current.UID == pod.UID {
+			return false, nil
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return h.isRolledOut(ctx, workload), nil
+	})
+	if err != nil {
+		return fmt.Errorf("pod %q was not replaced within %s: %w", pod.Name, h.rolloutTimeout(), err)
+	}
+	return nil
+}
+
+// workloadSelector returns the label selector of the pods of the workload.
+func workloadSelector(workload client.Object) (labels.Selector, error) {
+	var selector *metav1.LabelSelector
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		selector = w.Spec.Selector
+	case *appsv1.StatefulSet:
+		selector = w.Spec.Selector
+	case *appsv1.DaemonSet:
+		selector = w.Spec.Selector
+	case *appsv1.ReplicaSet:
+		selector = w.Spec.Selector
+	case *unstructured.Unstructured:
+		fields, found, err := unstructured.NestedMap(w.Object, "spec", "selector")
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			selector = &metav1.LabelSelector{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, selector); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %T", workload)
+	}
+	// An empty selector would match every pod of the namespace.
+	if selector == nil || (len(selector.MatchLabels) == 0 && len(selector.MatchExpressions) == 0) {
+		return nil, fmt.Errorf("%T %q has no pod selector", workload, workload.GetName())
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
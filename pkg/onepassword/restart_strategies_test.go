@@ -0,0 +1,414 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newStrategyTestDeployment(strategy RestartStrategy) *appsv1.Deployment {
+	replicas := int32(2)
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   namespace,
+			Annotations: map[string]string{RestartStrategyAnnotation: string(strategy)},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		},
+		Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+	}
+}
+
+func newStrategyTestPod(name string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, UID: types.UID("uid-" + name)}}
+}
+
+func TestRestartWorkloadEvictsPods(t *testing.T) {
+	previousInterval := rolloutCheckInterval
+	rolloutCheckInterval = 10 * time.Millisecond
+	defer func() { rolloutCheckInterval = previousInterval }()
+
+	blocked := 1
+	var evicted []string
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(
+			newStrategyTestDeployment(RestartStrategyEvict),
+			newStrategyTestPod("web-1", map[string]string{"app": "web"}),
+			newStrategyTestPod("web-2", map[string]string{"app": "web"}),
+			newStrategyTestPod("other", map[string]string{"app": "other"}),
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+				// The first eviction is refused, as a PodDisruptionBudget would.
+				if blocked > 0 {
+					blocked--
+					return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+				}
+				evicted = append(evicted, obj.GetName())
+				return c.SubResource(subResourceName).Create(ctx, obj, subResource, opts...)
+			},
+		}).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder}
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, deployment))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	require.NoError(t, h.restartWorkload(context.Background(), deployment, secret))
+
+	assert.Equal(t, []string{"web-1", "web-2"}, evicted)
+	pods := &corev1.PodList{}
+	require.NoError(t, cl.List(context.Background(), pods))
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, "other", pods.Items[0].Name)
+	assert.False(t, isRestarted(t, cl, "web"))
+	assert.Equal(t, []string{
+		"Normal WorkloadRestarted Evicted 2 pods to pick up changes to Secret \"secret\"",
+	}, drainEvents(recorder))
+}
+
+func TestRestartWorkloadReplacesReplicaSetPods(t *testing.T) {
+	previousInterval := rolloutCheckInterval
+	rolloutCheckInterval = 10 * time.Millisecond
+	defer func() { rolloutCheckInterval = previousInterval }()
+
+	replicas := int32(2)
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(
+			&appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+				Spec: appsv1.ReplicaSetSpec{
+					Replicas: &replicas,
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				},
+				Status: appsv1.ReplicaSetStatus{AvailableReplicas: 2, ObservedGeneration: 10},
+			},
+			newStrategyTestPod("web-1", map[string]string{"app": "web"}),
+			newStrategyTestPod("web-2", map[string]string{"app": "web"}),
+		).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder}
+	replicaSet := &appsv1.ReplicaSet{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, replicaSet))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	require.NoError(t, h.restartWorkload(context.Background(), replicaSet, secret))
+
+	// The template is updated for the pods recreated by the ReplicaSet, and the running pods are evicted.
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, replicaSet))
+	assert.Contains(t, replicaSet.Spec.Template.Annotations, RestartAnnotation)
+	pods := &corev1.PodList{}
+	require.NoError(t, cl.List(context.Background(), pods))
+	assert.Empty(t, pods.Items)
+	assert.Equal(t, []string{
+		"Normal WorkloadRestarted Evicted 2 pods to pick up changes to Secret \"secret\"",
+	}, drainEvents(recorder))
+}
+
+func TestRestartWorkloadsDoesNotWaitForEvictions(t *testing.T) {
+	previousInterval := rolloutCheckInterval
+	rolloutCheckInterval = time.Millisecond
+	defer func() { rolloutCheckInterval = previousInterval }()
+
+	var allowed atomic.Bool
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(
+			newStrategyTestDeployment(RestartStrategyEvict),
+			newStrategyTestPod("web-1", map[string]string{"app": "web"}),
+			newRestartTestDeployment("api", ""),
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			SubResourceCreate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, subResource client.Object, opts ...client.SubResourceCreateOption) error {
+				// Evictions are refused, as a PodDisruptionBudget would, until allowed.
+				if !allowed.Load() {
+					return apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+				}
+				return c.SubResource(subResourceName).Create(ctx, obj, subResource, opts...)
+			},
+		}).
+		Build()
+
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder}
+	web := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, web))
+	api := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "api"}, api))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	done := make(chan error)
+	go func() {
+		// The evicted workload comes first, and is still evicting when the other one is restarted.
+		done <- h.restartWorkloads(context.Background(), []pendingRestart{
+			{workload: web, secrets: []*corev1.Secret{secret}, priority: 1},
+			newPendingRestart(api, []*corev1.Secret{secret}),
+		})
+	}()
+
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "api") }, time.Second, time.Millisecond)
+	select {
+	case <-done:
+		t.Fatal("Restarts should wait for the eviction to complete")
+	default:
+	}
+
+	allowed.Store(true)
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Restarts did not complete")
+	}
+	pods := &corev1.PodList{}
+	require.NoError(t, cl.List(context.Background(), pods))
+	assert.Empty(t, pods.Items)
+	assert.ElementsMatch(t, []string{
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal WorkloadRestarted Evicted 1 pods to pick up changes to Secret \"secret\"",
+	}, drainEvents(recorder))
+}
+
+func TestRestartWorkloadWithCustomAnnotation(t *testing.T) {
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(newStrategyTestDeployment(RestartStrategyCustomAnnotation)).
+		Build()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace},
+		Data:       map[string][]byte{"password": []byte("new")},
+	}
+
+	testCases := map[string]struct {
+		config        SecretUpdateHandlerConfig
+		expectedError bool
+		expectedEvent string
+	}{
+		"annotation configured": {
+			config:        SecretUpdateHandlerConfig{CustomRestartAnnotation: "reloader.example.com/restartedAt"},
+			expectedEvent: "Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		},
+		"annotation not configured": {
+			expectedError: true,
+			expectedEvent: "Warning RestartFailed Failed to restart to pick up changes to Secret \"secret\": " +
+				"no custom restart annotation is configured for the annotation restart strategy",
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			recorder := record.NewFakeRecorder(10)
+			h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder, config: tc.config}
+			deployment := &appsv1.Deployment{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, deployment))
+
+			err := h.restartWorkload(context.Background(), deployment, secret)
+			if tc.expectedError {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: "web"}, deployment))
+				assert.Contains(t, deployment.Spec.Template.Annotations, tc.config.CustomRestartAnnotation)
+				assert.NotContains(t, deployment.Spec.Template.Annotations, RestartAnnotation)
+			}
+			assert.Equal(t, []string{tc.expectedEvent}, drainEvents(recorder))
+		})
+	}
+}
+
+func TestRestartWorkloadNotifies(t *testing.T) {
+	deployment := newStrategyTestDeployment(RestartStrategyNotify)
+	deployment.Annotations[RestartRequiredAnnotation] = "2006-01-02T15:04:05Z"
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).Build()
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), deployment))
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	notified := time.Now().Truncate(time.Second)
+	require.NoError(t, h.restartWorkload(context.Background(), deployment, secret))
+
+	updated := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), updated))
+	assert.Empty(t, updated.Spec.Template.Annotations)
+	since, required := restartRequiredSince(updated)
+	assert.True(t, required)
+	assert.False(t, since.Before(notified), "Annotation should hold the time of the latest notification")
+	assert.Equal(t, []string{
+		"Normal RestartRequired Restart to pick up changes to Secret \"secret\"",
+	}, drainEvents(recorder))
+}
+
+func TestClearRestartRequired(t *testing.T) {
+	notified := metav1.NewTime(time.Now().Add(-time.Hour).Truncate(time.Second))
+	before := metav1.NewTime(notified.Add(-time.Minute))
+	after := metav1.NewTime(notified.Add(time.Minute))
+
+	testCases := map[string]struct {
+		annotation       string
+		podsCreated      []metav1.Time
+		rollingOut       bool
+		expectedRequired bool
+	}{
+		"all pods replaced": {
+			annotation:  notified.UTC().Format(time.RFC3339),
+			podsCreated: []metav1.Time{after, after},
+		},
+		"pod not replaced": {
+			annotation:       notified.UTC().Format(time.RFC3339),
+			podsCreated:      []metav1.Time{after, before},
+			expectedRequired: true,
+		},
+		"rolling out": {
+			annotation:       notified.UTC().Format(time.RFC3339),
+			podsCreated:      []metav1.Time{after, after},
+			rollingOut:       true,
+			expectedRequired: true,
+		},
+		"invalid annotation": {
+			annotation:       "yesterday",
+			podsCreated:      []metav1.Time{after, after},
+			expectedRequired: true,
+		},
+		"no annotation": {
+			podsCreated: []metav1.Time{after, after},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			deployment := newStrategyTestDeployment(RestartStrategyNotify)
+			if tc.annotation != "" {
+				deployment.Annotations[RestartRequiredAnnotation] = tc.annotation
+			}
+			if tc.rollingOut {
+				deployment.Status.UpdatedReplicas = 1
+			}
+			objects := []client.Object{deployment}
+			for i, created := range tc.podsCreated {
+				pod := newStrategyTestPod(fmt.Sprintf("web-%d", i), map[string]string{"app": "web"})
+				pod.CreationTimestamp = created
+				objects = append(objects, pod)
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objects...).Build()
+			h := &SecretUpdateHandler{client: cl, apiReader: cl}
+
+			h.clearRestartRequired(context.Background())
+
+			updated := &appsv1.Deployment{}
+			require.NoError(t, cl.Get(context.Background(), client.ObjectKeyFromObject(deployment), updated))
+			if tc.expectedRequired {
+				assert.Equal(t, tc.annotation, updated.Annotations[RestartRequiredAnnotation])
+			} else {
+				assert.NotContains(t, updated.Annotations, RestartRequiredAnnotation)
+			}
+			assert.Equal(t, string(RestartStrategyNotify), updated.Annotations[RestartStrategyAnnotation])
+		})
+	}
+}
+
+func TestRestartStrategy(t *testing.T) {
+	testCases := map[string]struct {
+		annotations map[string]string
+		expected    RestartStrategy
+	}{
+		"unset": {
+			expected: RestartStrategyRolling,
+		},
+		"evict": {
+			annotations: map[string]string{RestartStrategyAnnotation: "evict"},
+			expected:    RestartStrategyEvict,
+		},
+		"annotation": {
+			annotations: map[string]string{RestartStrategyAnnotation: "annotation"},
+			expected:    RestartStrategyCustomAnnotation,
+		},
+		"notify": {
+			annotations: map[string]string{RestartStrategyAnnotation: "notify"},
+			expected:    RestartStrategyNotify,
+		},
+		"invalid": {
+			annotations: map[string]string{RestartStrategyAnnotation: "recreate"},
+			expected:    RestartStrategyRolling,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			assert.Equal(t, tc.expected, restartStrategy(deployment))
+		})
+	}
+}
+
+func TestWorkloadSelector(t *testing.T) {
+	matchLabels := &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}}
+	testCases := map[string]struct {
+		workload      client.Object
+		expected      string
+		expectedError bool
+	}{
+		"deployment": {
+			workload: &appsv1.Deployment{Spec: appsv1.DeploymentSpec{Selector: matchLabels}},
+			expected: "app=web",
+		},
+		"stateful set": {
+			workload: &appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Selector: matchLabels}},
+			expected: "app=web",
+		},
+		"custom resource": {
+			workload: &unstructured.Unstructured{Object: map[string]interface{}{
+				"spec": map[string]interface{}{
+					"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+				},
+			}},
+			expected: "app=web",
+		},
+		"empty selector": {
+			workload:      &appsv1.DaemonSet{Spec: appsv1.DaemonSetSpec{Selector: &metav1.LabelSelector{}}},
+			expectedError: true,
+		},
+		"custom resource without selector": {
+			workload:      &unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}},
+			expectedError: true,
+		},
+		"unsupported type": {
+			workload:      &policyv1.PodDisruptionBudget{},
+			expectedError: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			selector, err := workloadSelector(tc.workload)
+			if tc.expectedError {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, selector.String())
+		})
+	}
+}
@@ -0,0 +1,425 @@
+package onepassword
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
+)
+
+const defaultRolloutTimeout = 10 * time.Minute
+
+// rolloutCheckInterval is the time between two checks of a rollout in progress.
+var rolloutCheckInterval = 5 * time.Second
+
+// RestartMode decides which pod template annotations restart a workload.
+type RestartMode string
+
+const (
+	// RestartModeTimestamp sets the last-restarted annotation to the time of the restart.
+	RestartModeTimestamp RestartMode = "timestamp"
+	// RestartModeChecksum sets a secret-checksum-<name> annotation to the checksum of the data of each
+	// updated secret. Workloads are only restarted when the checksum of a secret they use changed, so the
+	// pod template does not change on every restart and restarting again for the same data does nothing.
+	RestartModeChecksum RestartMode = "checksum"
+)
+
+// ParseRestartMode parses the name of a restart mode, RestartModeTimestamp if empty.
+func ParseRestartMode(value string) (RestartMode, error) {
+	switch mode := RestartMode(value); mode {
+	case "":
+		return RestartModeTimestamp, nil
+	case RestartModeTimestamp, RestartModeChecksum:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown restart mode %q, must be %q or %q", value, RestartModeTimestamp, RestartModeChecksum)
+	}
+}
+
+// maxChecksumAnnotationNameLength is the maximum length of the name part of an annotation key.
+const maxChecksumAnnotationNameLength = 63
+
+// SecretChecksumAnnotation returns the pod template annotation holding the checksum of the named secret.
+// Names too long for an annotation key are shortened and suffixed with a hash of the full name.
+func SecretChecksumAnnotation(secretName string) string {
+	name := "secret-checksum-" + secretName
+	if len(name) > maxChecksumAnnotationNameLength {
+		hash := sha256.Sum256([]byte(secretName))
+		suffix := "-" + hex.EncodeToString(hash[:])[:8]
+		name = name[:maxChecksumAnnotationNameLength-len(suffix)] + suffix
+	}
+	return OnepasswordPrefix + "/" + name
+}
+
+// secretChecksum returns the checksum of the data of the secret.
+func secretChecksum(secret *corev1.Secret) string {
+	return kubeSecrets.ContentHash(secret.Data)
+}
+
+// isSecretChangedForPodTemplate reports whether pods created from the template would see different data
+// in the secret. It is always true unless restarting with checksums.
+func (h *SecretUpdateHandler) isSecretChangedForPodTemplate(podTemplate *corev1.PodTemplateSpec, secret *corev1.Secret) bool {
+	if h.config.RestartMode != RestartModeChecksum {
+		return true
+	}
+	return podTemplate.Annotations[SecretChecksumAnnotation(secret.Name)] != secretChecksum(secret)
+}
+
+// setRestartAnnotations changes the pod template of the workload to restart it.
+func (h *SecretUpdateHandler) setRestartAnnotations(workload client.Object, secrets []*corev1.Secret) error {
+	if h.config.RestartMode != RestartModeChecksum {
+		return h.setPodTemplateAnnotation(workload, RestartAnnotation, time.Now().Format(time.RFC3339))
+	}
+	for _, secret := range secrets {
+		if err := h.setPodTemplateAnnotation(workload, SecretChecksumAnnotation(secret.Name), secretChecksum(secret)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// describeSecrets returns the quoted names of the secrets for event messages.
+func describeSecrets(secrets []*corev1.Secret) string {
+	names := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		names = append(names, strconv.Quote(secret.Name))
+	}
+	if len(names) == 1 {
+		return "Secret " + names[0]
+	}
+	return "Secrets " + strings.Join(names, ", ")
+}
+
+// pendingRestart is a workload to restart because it uses updated secrets.
+type pendingRestart struct {
+	workload client.Object
+	secrets  []*corev1.Secret
+	priority int
+}
+
+func newPendingRestart(workload client.Object, secrets []*corev1.Secret) pendingRestart {
+	return pendingRestart{workload: workload, secrets: secrets, priority: restartPriority(workload)}
+}
+
+// restartPriority returns the value of the restart-priority annotation of the workload, 0 if unset or invalid.
+func restartPriority(workload client.Object) int {
+	value, found := workload.GetAnnotations()[RestartPriorityAnnotation]
+	if !found {
+		return 0
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error parsing %v annotation on %T %v. Must be an integer.",
+			RestartPriorityAnnotation, workload, workload.GetName()))
+		return 0
+	}
+	return priority
+}
+
+// restartQueue holds the restarts found by poll runs until the restart worker takes them, so that
+// polling does not wait for restarts, rollouts and evictions.
+type restartQueue struct {
+	mu       sync.Mutex
+	restarts []pendingRestart
+	// index is the position of the queued restart of each workload.
+	index map[string]int
+	// ready holds a value when the worker has restarts to look at.
+	ready chan struct{}
+}
+
+// readyChan returns the channel the worker is woken up on.
+func (q *restartQueue) readyChan() chan struct{} {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.ready == nil {
+		q.ready = make(chan struct{}, 1)
+	}
+	return q.ready
+}
+
+// take removes and returns the queued restarts.
+func (q *restartQueue) take() []pendingRestart {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	restarts := q.restarts
+	q.restarts = nil
+	q.index = nil
+	return restarts
+}
+
+// queueRestarts queues the restarts for the restart worker and wakes it up, even without restarts so
+// that it starts the throttled restarts that are now due. Restarts of a workload that is already queued
+// are merged into the queued restart.
+func (h *SecretUpdateHandler) queueRestarts(restarts []pendingRestart) {
+	q := &h.restartQueue
+	ready := q.readyChan()
+
+	q.mu.Lock()
+	if q.index == nil {
+		q.index = map[string]int{}
+	}
+	for _, restart := range restarts {
+		key := h.workloadKey(restart.workload)
+		if i, found := q.index[key]; found {
+			queued := &q.restarts[i]
+			queued.workload = restart.workload
+			queued.priority = restart.priority
+			queued.secrets = mergeSecrets(queued.secrets, restart.secrets)
+			continue
+		}
+		q.index[key] = len(q.restarts)
+		q.restarts = append(q.restarts, restart)
+	}
+	q.mu.Unlock()
+
+	select {
+	case ready <- struct{}{}:
+	default:
+	}
+}
+
+// runRestarts starts the queued restarts each time restarts are queued, until ctx is cancelled.
+func (h *SecretUpdateHandler) runRestarts(ctx context.Context) {
+	ready := h.restartQueue.readyChan()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ready:
+			h.processRestarts(ctx)
+		}
+	}
+}
+
+// processRestarts throttles the queued restarts and restarts the workloads that are due, waiting for
+// their rollouts and evictions. Restarts queued in the meantime wait for the next call. It then clears
+// the RestartRequired condition of the workloads restarted since they were notified.
+func (h *SecretUpdateHandler) processRestarts(ctx context.Context) {
+	if err := h.restartWorkloads(ctx, h.throttleRestarts(ctx, h.restartQueue.take())); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error(err, "Failed to restart workloads")
+	}
+	h.clearRestartRequired(ctx)
+}
+
+// restartsStaggered reports whether restarts are spread over time instead of all being started at once.
+func (h *SecretUpdateHandler) restartsStaggered() bool {
+	return h.config.MaxConcurrentRestarts > 0 || h.config.RestartInterval > 0
+}
+
+func (h *SecretUpdateHandler) rolloutTimeout() time.Duration {
+	if h.config.RolloutTimeout > 0 {
+		return h.config.RolloutTimeout
+	}
+	return defaultRolloutTimeout
+}
+
+// restartWorkloads restarts the workloads by decreasing priority.
+//
+// When MaxConcurrentRestarts is set, a workload is only restarted once fewer workloads than that are
+// still rolling out, and when RestartInterval is set, at least that long after the previous restart and
+// once the previous rollout ended, unless MaxConcurrentRestarts allows more.
+// Failing to restart a workload does not stop the others from being restarted. Workloads whose pods are
+// evicted are restarted alongside the other restarts, as evictions wait for each pod to be replaced.
+func (h *SecretUpdateHandler) restartWorkloads(ctx context.Context, restarts []pendingRestart) error {
+	slices.SortStableFunc(restarts, func(a, b pendingRestart) int {
+		return b.priority - a.priority
+	})
+
+	var rollouts sync.WaitGroup
+	defer rollouts.Wait()
+
+	if !h.restartsStaggered() {
+		for _, restart := range restarts {
+			if !evictsPods(restart.workload) {
+				h.restartPendingWorkload(ctx, restart)
+				continue
+			}
+			rollouts.Add(1)
+			go func() {
+				defer rollouts.Done()
+				h.restartPendingWorkload(ctx, restart)
+			}()
+		}
+		return nil
+	}
+
+	if len(restarts) > 1 {
+		for i, restart := range restarts[1:] {
+			h.recordEvent(restart.workload, corev1.EventTypeNormal, ReasonRestartQueued,
+				fmt.Sprintf("Restart to pick up changes to %s queued at position %d of %d",
+					describeSecrets(restart.secrets), i+2, len(restarts)))
+		}
+	}
+
+	// Without MaxConcurrentRestarts, restarts spread by RestartInterval still wait for the previous rollout.
+	slots := make(chan struct{}, max(h.config.MaxConcurrentRestarts, 1))
+	release := func() { <-slots }
+
+	var lastRestart time.Time
+	for _, restart := range restarts {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if !lastRestart.IsZero() {
+			if err := sleep(ctx, h.config.RestartInterval-time.Since(lastRestart)); err != nil {
+				release()
+				return err
+			}
+		}
+
+		if evictsPods(restart.workload) {
+			lastRestart = time.Now()
+			rollouts.Add(1)
+			go func() {
+				defer rollouts.Done()
+				defer release()
+				if h.restartPendingWorkload(ctx, restart) {
+					h.waitForRollout(ctx, restart.workload)
+				}
+			}()
+			continue
+		}
+
+		if !h.restartPendingWorkload(ctx, restart) {
+			release()
+			continue
+		}
+		lastRestart = time.Now()
+
+		// Workloads left running are not rolling out.
+		if restartStrategy(restart.workload) == RestartStrategyNotify {
+			release()
+			continue
+		}
+		rollouts.Add(1)
+		go func() {
+			defer rollouts.Done()
+			defer release()
+			h.waitForRollout(ctx, restart.workload)
+		}()
+	}
+	return nil
+}
+
+// restartPendingWorkload restarts the workload and reports whether it was restarted.
+func (h *SecretUpdateHandler) restartPendingWorkload(ctx context.Context, restart pendingRestart) bool {
+	if err := h.restartWorkload(ctx, restart.workload, restart.secrets...); err != nil {
+		log.Error(err, "Failed to restart workload",
+			"workload", restart.workload.GetName(), "namespace", restart.workload.GetNamespace())
+		return false
+	}
+	return true
+}
+
+// waitForRollout waits until all pods of the restarted workload are updated and available, or the
+// rollout timeout passed, and records the outcome as an event.
+func (h *SecretUpdateHandler) waitForRollout(ctx context.Context, workload client.Object) {
+	start := time.Now()
+	timeout := h.rolloutTimeout()
+
+	err := wait.PollUntilContextTimeout(ctx, rolloutCheckInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		return h.isRolledOut(ctx, workload), nil
+	})
+	switch {
+	case err == nil:
+		h.recordEvent(workload, corev1.EventTypeNormal, ReasonRolloutCompleted,
+			fmt.Sprintf("Rollout completed after %s", time.Since(start).Round(time.Second)))
+	case ctx.Err() == nil:
+		h.recordEvent(workload, corev1.EventTypeWarning, ReasonRolloutTimedOut,
+			fmt.Sprintf("Rollout did not complete within %s, continuing with the next restarts", timeout))
+	}
+}
+
+// isRolledOut gets the latest state of the workload and reports whether its rollout completed.
+func (h *SecretUpdateHandler) isRolledOut(ctx context.Context, workload client.Object) bool {
+	latest, ok := workload.DeepCopyObject().(client.Object)
+	if !ok {
+		return true
+	}
+	if err := h.client.Get(ctx, client.ObjectKeyFromObject(workload), latest); err != nil {
+		log.Error(err, "Failed to get the rollout status of workload",
+			"workload", workload.GetName(), "namespace", workload.GetNamespace())
+		return false
+	}
+	return isRolloutComplete(latest)
+}
+
+// isRolloutComplete reports whether the controller of the workload observed its latest change and
+// replaced all of its pods. Workloads whose pods are not replaced on changes are complete once their
+// pods are available.
+func isRolloutComplete(workload client.Object) bool {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		replicas := replicasOrDefault(w.Spec.Replicas)
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.Replicas == replicas &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.AvailableReplicas == replicas
+	case *appsv1.StatefulSet:
+		if w.Spec.UpdateStrategy.Type == appsv1.OnDeleteStatefulSetStrategyType {
+			return true
+		}
+		replicas := replicasOrDefault(w.Spec.Replicas)
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.UpdatedReplicas == replicas &&
+			w.Status.ReadyReplicas == replicas
+	case *appsv1.DaemonSet:
+		if w.Spec.UpdateStrategy.Type == appsv1.OnDeleteDaemonSetStrategyType {
+			return true
+		}
+		return w.Status.ObservedGeneration >= w.Generation &&
+			w.Status.UpdatedNumberScheduled == w.Status.DesiredNumberScheduled &&
+			w.Status.NumberAvailable == w.Status.DesiredNumberScheduled
+	case *appsv1.ReplicaSet:
+		replicas := replicasOrDefault(w.Spec.Replicas)
+		return w.Status.ObservedGeneration >= w.Generation && w.Status.AvailableReplicas == replicas
+	case *unstructured.Unstructured:
+		observedGeneration, found, err := unstructured.NestedInt64(w.Object, "status", "observedGeneration")
+		if !found || err != nil {
+			return true
+		}
+		return observedGeneration >= w.GetGeneration()
+	default:
+		return true
+	}
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// sleep waits for d, or until ctx is cancelled.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,499 @@
+package onepassword
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newRestartTestDeployment(name string, priority string) *appsv1.Deployment {
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+	}
+	if priority != "" {
+		deployment.Annotations = map[string]string{RestartPriorityAnnotation: priority}
+	}
+	return deployment
+}
+
+func setRolloutComplete(t *testing.T, cl client.Client, name string) {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, deployment))
+	deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	require.NoError(t, cl.Status().Update(context.Background(), deployment))
+}
+
+func isRestarted(t *testing.T, cl client.Client, name string) bool {
+	t.Helper()
+	deployment := &appsv1.Deployment{}
+	require.NoError(t, cl.Get(context.Background(), client.ObjectKey{Namespace: namespace, Name: name}, deployment))
+	_, found := deployment.Spec.Template.Annotations[RestartAnnotation]
+	return found
+}
+
+func TestRestartWorkloadsByPriority(t *testing.T) {
+	defer func(interval time.Duration) { rolloutCheckInterval = interval }(rolloutCheckInterval)
+	rolloutCheckInterval = time.Millisecond
+
+	deployments := []*appsv1.Deployment{
+		newRestartTestDeployment("default", ""),
+		newRestartTestDeployment("low", "-1"),
+		newRestartTestDeployment("high", "10"),
+		newRestartTestDeployment("invalid", "first"),
+		newRestartTestDeployment("medium", "5"),
+	}
+
+	var mu sync.Mutex
+	var restarted []string
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+				mu.Lock()
+				restarted = append(restarted, obj.GetName())
+				mu.Unlock()
+				return c.Update(ctx, obj, opts...)
+			},
+		}).
+		Build()
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+	var restarts []pendingRestart
+	for _, deployment := range deployments {
+		deployment.Status = appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+		require.NoError(t, cl.Create(context.Background(), deployment))
+		restarts = append(restarts, newPendingRestart(deployment, []*corev1.Secret{secret}))
+	}
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		RestartInterval: time.Millisecond,
+	}}
+	require.NoError(t, h.restartWorkloads(context.Background(), restarts))
+
+	assert.Equal(t, []string{"high", "medium", "default", "invalid", "low"}, restarted)
+	assert.Equal(t, []string{
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 2 of 5",
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 3 of 5",
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 4 of 5",
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 5 of 5",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+	}, drainEvents(recorder))
+}
+
+func TestRestartWorkloadsWaitsForRollout(t *testing.T) {
+	defer func(interval time.Duration) { rolloutCheckInterval = interval }(rolloutCheckInterval)
+	rolloutCheckInterval = time.Millisecond
+
+	first := newRestartTestDeployment("first", "1")
+	second := newRestartTestDeployment("second", "")
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(first, second).Build()
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		MaxConcurrentRestarts: 1,
+	}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	done := make(chan error)
+	go func() {
+		done <- h.restartWorkloads(context.Background(), []pendingRestart{
+			newPendingRestart(second, []*corev1.Secret{secret}),
+			newPendingRestart(first, []*corev1.Secret{secret}),
+		})
+	}()
+
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "first") }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, isRestarted(t, cl, "second"), "Second workload should wait for the rollout of the first one")
+
+	setRolloutComplete(t, cl, "first")
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "second") }, time.Second, time.Millisecond)
+	setRolloutComplete(t, cl, "second")
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Restarts did not complete")
+	}
+
+	assert.Equal(t, []string{
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 2 of 2",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+	}, drainEvents(recorder))
+}
+
+func TestRestartWorkloadsWithIntervalWaitsForRollout(t *testing.T) {
+	defer func(interval time.Duration) { rolloutCheckInterval = interval }(rolloutCheckInterval)
+	rolloutCheckInterval = time.Millisecond
+
+	first := newRestartTestDeployment("first", "1")
+	second := newRestartTestDeployment("second", "")
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(first, second).Build()
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		RestartInterval: time.Millisecond,
+	}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+
+	done := make(chan error)
+	go func() {
+		done <- h.restartWorkloads(context.Background(), []pendingRestart{
+			newPendingRestart(second, []*corev1.Secret{secret}),
+			newPendingRestart(first, []*corev1.Secret{secret}),
+		})
+	}()
+
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "first") }, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.False(t, isRestarted(t, cl, "second"), "Second workload should wait for the rollout of the first one")
+
+	setRolloutComplete(t, cl, "first")
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "second") }, time.Second, time.Millisecond)
+	setRolloutComplete(t, cl, "second")
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Restarts did not complete")
+	}
+}
+
+func TestRestartWorkloadsRolloutTimeout(t *testing.T) {
+	defer func(interval time.Duration) { rolloutCheckInterval = interval }(rolloutCheckInterval)
+	rolloutCheckInterval = time.Millisecond
+
+	first := newRestartTestDeployment("first", "1")
+	second := newRestartTestDeployment("second", "")
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(first, second).Build()
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		MaxConcurrentRestarts: 1,
+		RolloutTimeout:        10 * time.Millisecond,
+	}}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+	require.NoError(t, h.restartWorkloads(context.Background(), []pendingRestart{
+		newPendingRestart(first, []*corev1.Secret{secret}),
+		newPendingRestart(second, []*corev1.Secret{secret}),
+	}))
+
+	assert.True(t, isRestarted(t, cl, "second"), "Restarts should continue after a rollout timed out")
+	assert.Equal(t, []string{
+		"Normal RestartQueued Restart to pick up changes to Secret \"secret\" queued at position 2 of 2",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Warning RolloutTimedOut Rollout did not complete within 10ms, continuing with the next restarts",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"secret\"",
+		"Warning RolloutTimedOut Rollout did not complete within 10ms, continuing with the next restarts",
+	}, drainEvents(recorder))
+}
+
+func TestRestartQueue(t *testing.T) {
+	defer func(interval time.Duration) { rolloutCheckInterval = interval }(rolloutCheckInterval)
+	rolloutCheckInterval = time.Millisecond
+
+	deployment := newRestartTestDeployment("web", "")
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).Build()
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		MaxConcurrentRestarts: 1,
+	}}
+	first := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace}}
+	second := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace}}
+
+	// Restarts of a workload queued by several runs are merged.
+	h.queueRestarts([]pendingRestart{newPendingRestart(deployment, []*corev1.Secret{first})})
+	h.queueRestarts([]pendingRestart{newPendingRestart(deployment, []*corev1.Secret{second})})
+	assert.False(t, isRestarted(t, cl, "web"), "Queueing should not restart workloads")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.runRestarts(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return isRestarted(t, cl, "web") }, time.Second, time.Millisecond)
+	setRolloutComplete(t, cl, "web")
+	require.Eventually(t, func() bool { return len(recorder.Events) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{
+		"Normal WorkloadRestarted Restarted to pick up changes to Secrets \"first\", \"second\"",
+		"Normal RolloutCompleted Rollout completed after 0s",
+	}, drainEvents(recorder))
+	assert.Empty(t, h.restartQueue.take())
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Restart worker did not stop")
+	}
+}
+
+func TestIsRolloutComplete(t *testing.T) {
+	replicas := int32(2)
+	testCases := map[string]struct {
+		workload client.Object
+		expected bool
+	}{
+		"deployment rolled out": {
+			workload: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+			expected: true,
+		},
+		"deployment change not observed": {
+			workload: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Generation: 3},
+				Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+				Status:     appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+		},
+		"deployment with old pods": {
+			workload: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{Replicas: 3, UpdatedReplicas: 2, AvailableReplicas: 2},
+			},
+		},
+		"deployment with unavailable pods": {
+			workload: &appsv1.Deployment{
+				Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+				Status: appsv1.DeploymentStatus{Replicas: 2, UpdatedReplicas: 2, AvailableReplicas: 1},
+			},
+		},
+		"stateful set rolling out": {
+			workload: &appsv1.StatefulSet{
+				Spec:   appsv1.StatefulSetSpec{Replicas: &replicas},
+				Status: appsv1.StatefulSetStatus{UpdatedReplicas: 1, ReadyReplicas: 2},
+			},
+		},
+		"stateful set updated on delete": {
+			workload: &appsv1.StatefulSet{
+				Spec: appsv1.StatefulSetSpec{
+					Replicas:       &replicas,
+					UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.OnDeleteStatefulSetStrategyType},
+				},
+			},
+			expected: true,
+		},
+		"daemon set rolled out": {
+			workload: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 3},
+			},
+			expected: true,
+		},
+		"daemon set rolling out": {
+			workload: &appsv1.DaemonSet{
+				Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2},
+			},
+		},
+		"replica set available": {
+			workload: &appsv1.ReplicaSet{
+				Status: appsv1.ReplicaSetStatus{AvailableReplicas: 1},
+			},
+			expected: true,
+		},
+		"replica set replacing pods": {
+			workload: &appsv1.ReplicaSet{
+				Status: appsv1.ReplicaSetStatus{AvailableReplicas: 0},
+			},
+		},
+		"custom resource change not observed": {
+			workload: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+				"status":   map[string]interface{}{"observedGeneration": int64(1)},
+			}},
+		},
+		"custom resource without status": {
+			workload: &unstructured.Unstructured{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{"generation": int64(2)},
+			}},
+			expected: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, isRolloutComplete(tc.workload))
+		})
+	}
+}
+
+func TestRestartWorkloadsWithChecksums(t *testing.T) {
+	ctx := context.Background()
+	first := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: namespace},
+		Data:       map[string][]byte{"password": []byte("new")},
+	}
+	second := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "second", Namespace: namespace},
+		Data:       map[string][]byte{"token": []byte("new")},
+	}
+	newDeployment := func(name string, annotations map[string]string, secrets ...string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Annotations: annotations},
+				Spec:       corev1.PodSpec{Containers: generateContainersWithSecretRefsFromEnv(secrets)},
+			}},
+		}
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		defaultNamespace,
+		newDeployment("up-to-date", map[string]string{SecretChecksumAnnotation("first"): secretChecksum(first)}, "first"),
+		newDeployment("outdated", map[string]string{SecretChecksumAnnotation("first"): "old"}, "first"),
+		newDeployment("both", nil, "first", "second"),
+	).Build()
+
+	recorder := record.NewFakeRecorder(20)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder, config: SecretUpdateHandlerConfig{
+		ShouldAutoRestartWorkloadsGlobally: true,
+		RestartMode:                        RestartModeChecksum,
+	}}
+	updatedSecrets := map[string]map[string]*corev1.Secret{namespace: {"first": first, "second": second}}
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(ctx, updatedSecrets, nil))
+	h.processRestarts(ctx)
+
+	expectedAnnotations := map[string]map[string]string{
+		"up-to-date": {SecretChecksumAnnotation("first"): secretChecksum(first)},
+		"outdated":   {SecretChecksumAnnotation("first"): secretChecksum(first)},
+		"both": {
+			SecretChecksumAnnotation("first"):  secretChecksum(first),
+			SecretChecksumAnnotation("second"): secretChecksum(second),
+		},
+	}
+	for name, expected := range expectedAnnotations {
+		deployment := &appsv1.Deployment{}
+		require.NoError(t, cl.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, deployment))
+		assert.Equal(t, expected, deployment.Spec.Template.Annotations, name)
+	}
+	assert.ElementsMatch(t, []string{
+		"Normal WorkloadRestarted Restarted to pick up changes to Secret \"first\"",
+		"Normal WorkloadRestarted Restarted to pick up changes to Secrets \"first\", \"second\"",
+	}, drainEvents(recorder))
+
+	// Another replica or a retry handling the same update restarts nothing.
+	require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(ctx, updatedSecrets, nil))
+	h.processRestarts(ctx)
+	assert.Empty(t, drainEvents(recorder))
+}
+
+func TestSecretChecksumAnnotation(t *testing.T) {
+	assert.Equal(t, "operator.1password.io/secret-checksum-db-credentials", SecretChecksumAnnotation("db-credentials"))
+
+	long := strings.Repeat("a", 100)
+	annotation := SecretChecksumAnnotation(long)
+	name := strings.TrimPrefix(annotation, OnepasswordPrefix+"/")
+	assert.Len(t, name, maxChecksumAnnotationNameLength)
+	assert.NotEqual(t, annotation, SecretChecksumAnnotation(long+"b"), "Shortened names should stay unique")
+	assert.Empty(t, validation.IsQualifiedName(annotation))
+}
+
+func TestParseRestartMode(t *testing.T) {
+	for value, expected := range map[string]RestartMode{
+		"":          RestartModeTimestamp,
+		"timestamp": RestartModeTimestamp,
+		"checksum":  RestartModeChecksum,
+	} {
+		mode, err := ParseRestartMode(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, mode, value)
+	}
+	_, err := ParseRestartMode("hash")
+	assert.Error(t, err)
+}
+
+func TestRestartWorkloadsWithLiveMounts(t *testing.T) {
+	testCases := map[string]struct {
+		skipLiveMounts  bool
+		annotations     map[string]string
+		subPath         string
+		expectedRestart bool
+	}{
+		"restarted by default": {
+			expectedRestart: true,
+		},
+		"skipped": {
+			skipLiveMounts: true,
+		},
+		"restart forced by annotation": {
+			skipLiveMounts:  true,
+			annotations:     map[string]string{RestartLiveMountsAnnotation: "true"},
+			expectedRestart: true,
+		},
+		"skipped by annotation": {
+			annotations: map[string]string{RestartLiveMountsAnnotation: "false"},
+		},
+		"mounted with subPath": {
+			skipLiveMounts:  true,
+			subPath:         "password",
+			expectedRestart: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			ctx := context.Background()
+			deployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: namespace, Annotations: tc.annotations},
+				Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						VolumeMounts: []corev1.VolumeMount{{Name: "secret", MountPath: "/secret", SubPath: tc.subPath}},
+					}},
+					Volumes: []corev1.Volume{{Name: "secret", VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: "secret"},
+					}}},
+				}}},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(defaultNamespace, deployment).Build()
+
+			h := &SecretUpdateHandler{client: cl, apiReader: cl, config: SecretUpdateHandlerConfig{
+				ShouldAutoRestartWorkloadsGlobally: true,
+				SkipRestartsForLiveMounts:          tc.skipLiveMounts,
+			}}
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+			require.NoError(t, h.restartWorkloadsWithUpdatedSecrets(ctx,
+				map[string]map[string]*corev1.Secret{namespace: {"secret": secret}},
+				map[types.NamespacedName][]string{client.ObjectKeyFromObject(secret): {"password"}},
+			))
+			h.processRestarts(ctx)
+			assert.Equal(t, tc.expectedRestart, isRestarted(t, cl, "deployment"))
+		})
+	}
+}
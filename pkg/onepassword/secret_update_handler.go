@@ -2,21 +2,37 @@ package onepassword
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"golang.org/x/sync/errgroup"
 	"k8s.io/apimachinery/pkg/api/meta"
 
 	onepasswordv1 "github.com/1Password/onepassword-operator/api/v1"
 	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
 	"github.com/1Password/onepassword-operator/pkg/logs"
 	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/ratelimit"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing"
 	"github.com/1Password/onepassword-operator/pkg/utils"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -26,21 +42,58 @@ const lockTag = "operator.1password.io:ignore-secret"
 var log = logf.Log.WithName("update_op_kubernetes_secrets_task")
 
 type SecretUpdateHandlerConfig struct {
+	// PollingInterval is the time between two updates of the kubernetes secrets, 10 minutes if unset.
+	PollingInterval                    time.Duration
 	ShouldAutoRestartWorkloadsGlobally bool
 	AllowEmptyValues                   bool
 	WatchedNamespaces                  []string
+	// ExtraWorkloadTypes are restarted in addition to Deployments, StatefulSets, DaemonSets and ReplicaSets.
+	ExtraWorkloadTypes []WorkloadType
+	// MaxConcurrentRestarts is the number of restarted workloads allowed to roll out at the same time.
+	// The next workload is restarted once a rollout completed or timed out. Unlimited if unset.
+	MaxConcurrentRestarts int
+	// RestartInterval is the minimum time between the restarts of two workloads.
+	RestartInterval time.Duration
+	// RolloutTimeout bounds waiting for the rollout of a restarted workload, 10 minutes if unset.
+	RolloutTimeout time.Duration
+	// RestartDebounceWindow delays the restart of a workload by this long after the first update it needs
+	// to pick up, so that the updates seen in the meantime are picked up by the same restart.
+	RestartDebounceWindow time.Duration
+	// MaxRestartsPerHour opens the circuit breaker of a workload restarted this many times within the last
+	// hour, which pauses its restarts. Unlimited if unset.
+	MaxRestartsPerHour int
+	// RestartMode decides how workloads are restarted, RestartModeTimestamp if unset.
+	RestartMode RestartMode
+	// CustomRestartAnnotation is the pod template annotation set on workloads with the annotation restart strategy.
+	CustomRestartAnnotation string
+	// SkipRestartsForLiveMounts leaves out workloads that only use updated secrets through volumes
+	// mounted without subPath, which the kubelet updates in running pods.
+	SkipRestartsForLiveMounts bool
+	// PollingConcurrency is the number of secrets updated in parallel, 5 if unset.
+	PollingConcurrency int
+	// BatchByVault lists each vault once per run and only fetches the items whose version changed,
+	// instead of fetching every item.
+	BatchByVault bool
+	// ItemTimeout bounds fetching an item and its files from 1Password, 30 seconds if unset.
+	ItemTimeout time.Duration
+	// RetryPolicy decides how long to pause polling after 1Password rate limited or failed to answer requests.
+	RetryPolicy retry.Policy
+	// OperatorPod is the pod of the operator, which failed runs are recorded on as events. Not recorded if unset.
+	OperatorPod *corev1.ObjectReference
 }
 
 func NewSecretUpdateHandler(
 	kubernetesClient client.Client,
 	apiReader client.Reader,
 	opClient opclient.Client,
+	recorder record.EventRecorder,
 	config SecretUpdateHandlerConfig,
 ) *SecretUpdateHandler {
 	return &SecretUpdateHandler{
 		client:    kubernetesClient,
 		apiReader: apiReader,
 		opClient:  opClient,
+		recorder:  recorder,
 		config:    config,
 	}
 }
@@ -49,46 +102,165 @@ type SecretUpdateHandler struct {
 	client    client.Client
 	apiReader client.Reader
 	opClient  opclient.Client
+	recorder  record.EventRecorder
 	config    SecretUpdateHandlerConfig
+
+	// failures counts the consecutive runs that stopped on a transient error,
+	// no run is started before retryAt.
+	failures int
+	retryAt  time.Time
+	// knownItems are the items fetched in previous runs when batching by vault.
+	knownItems map[string]knownItem
+	// restartQueue holds the restarts found by poll runs until the restart worker takes them.
+	restartQueue restartQueue
+	// restartHistory holds the recent and pending restarts of each workload. It is only used by the
+	// restart worker.
+	restartHistory restartHistory
+
+	status pollerStatus
 }
 
-func (h *SecretUpdateHandler) UpdateKubernetesSecretsTask(ctx context.Context) error {
-	updatedKubernetesSecrets, err := h.updateKubernetesSecrets(ctx)
+func (h *SecretUpdateHandler) UpdateKubernetesSecretsTask(ctx context.Context) (err error) {
+	if time.Now().Before(h.retryAt) {
+		log.V(logs.DebugLevel).Info(fmt.Sprintf("Backing off from 1Password until %s", h.retryAt.Format(time.RFC3339)))
+		return nil
+	}
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "SecretUpdateHandler.UpdateKubernetesSecretsTask")
+	defer func() {
+		pollDurationSeconds.Observe(time.Since(start).Seconds())
+		tracing.End(span, err)
+	}()
+
+	updatedKubernetesSecrets, changedKeys, err := h.updateKubernetesSecrets(ctx)
 	if err != nil {
 		return err
 	}
 
-	return h.restartWorkloadsWithUpdatedSecrets(ctx, updatedKubernetesSecrets)
+	return h.restartWorkloadsWithUpdatedSecrets(ctx, updatedKubernetesSecrets, changedKeys)
 }
 
+// backOff pauses polling after a run stopped because of err.
+func (h *SecretUpdateHandler) backOff(err error) {
+	delay := h.config.RetryPolicy.Delay(h.failures, err)
+	h.failures++
+	h.retryAt = time.Now().Add(delay)
+	log.Info(fmt.Sprintf("Pausing updates of kubernetes secrets for %s: %s", delay.Round(time.Second), err))
+}
+
+// restartWorkloadsWithUpdatedSecrets queues the restarts of the workloads using updated secrets for the
+// restart worker, see processRestarts. Workloads reading
+// single keys of a secret are only restarted when one of these keys is in changedKeys, secrets
+// missing from changedKeys are considered to have changed entirely.
 func (h *SecretUpdateHandler) restartWorkloadsWithUpdatedSecrets(
 	ctx context.Context,
 	updatedSecretsByNamespace map[string]map[string]*corev1.Secret,
+	changedKeys map[types.NamespacedName][]string,
 ) error {
-	// No secrets to update. Exit
-	if len(updatedSecretsByNamespace) == 0 || updatedSecretsByNamespace == nil {
+	// No secrets to update. Only start the restarts held back in earlier runs that are now due.
+	if len(updatedSecretsByNamespace) == 0 {
+		h.queueRestarts(nil)
 		return nil
 	}
 
-	workloadTypes := []client.ObjectList{
-		&appsv1.DeploymentList{},
+	setForAutoRestartByNamespaceMap, err := h.getIsSetForAutoRestartByNamespaceMap(ctx)
+	if err != nil {
+		return err
 	}
 
-	setForAutoRestartByNamespaceMap, err := h.getIsSetForAutoRestartByNamespaceMap(ctx)
+	workloads, err := h.listWorkloads(ctx)
 	if err != nil {
 		return err
 	}
 
+	var restarts []pendingRestart
+	for _, workload := range workloads {
+		podTemplate, err := h.getPodTemplate(workload)
+		if err != nil {
+			log.Error(err, "Failed to get pod template", "workload", workload.GetName())
+			continue
+		}
+
+		updatedSecrets := updatedSecretsByNamespace[workload.GetNamespace()]
+		if len(updatedSecrets) == 0 {
+			continue
+		}
+
+		matchedSecrets := getUpdatedSecretsForPodTemplate(workload.GetAnnotations(), podTemplate, updatedSecrets)
+		if len(matchedSecrets) == 0 {
+			continue
+		}
+
+		usages := getSecretUsages(podTemplate)
+		skipLiveMounts := h.shouldSkipRestartsForLiveMounts(workload)
+		var restartSecrets []*corev1.Secret
+		for _, name := range slices.Sorted(maps.Keys(matchedSecrets)) {
+			secret := matchedSecrets[name]
+			if !isSecretSetForAutoRestart(secret, workload, setForAutoRestartByNamespaceMap) ||
+				!h.isSecretChangedForPodTemplate(podTemplate, secret) {
+				continue
+			}
+			// Keys are nil when unknown. Secrets only referenced by the annotations of the workload
+			// may be used in any way.
+			keys := changedKeys[client.ObjectKeyFromObject(secret)]
+			if usage := usages[name]; usage != nil && !usage.needsRestart(keys, skipLiveMounts) {
+				log.V(logs.DebugLevel).Info(fmt.Sprintf(
+					"%T %q at namespace %q does not need a restart to pick up changes to keys %v of secret %q",
+					workload, workload.GetName(), workload.GetNamespace(), keys, name))
+				continue
+			}
+			restartSecrets = append(restartSecrets, secret)
+		}
+		if len(restartSecrets) > 0 {
+			restarts = append(restarts, newPendingRestart(workload, restartSecrets))
+			continue
+		}
+
+		log.V(logs.DebugLevel).Info(
+			fmt.Sprintf("%T %q at namespace %q is up to date", workload, workload.GetName(), workload.GetNamespace()),
+		)
+	}
+
+	h.queueRestarts(restarts)
+	return nil
+}
+
+// listWorkloads lists the workloads that may use secrets. ReplicaSets managed by a Deployment are left
+// out, as they are restarted through their Deployment.
+func (h *SecretUpdateHandler) listWorkloads(ctx context.Context) ([]client.Object, error) {
+	workloadTypes := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&appsv1.DaemonSetList{},
+		&appsv1.ReplicaSetList{},
+	}
+	for _, workloadType := range h.config.ExtraWorkloadTypes {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(workloadType.GroupVersionKind.GroupVersion().WithKind(workloadType.GroupVersionKind.Kind + "List"))
+		workloadTypes = append(workloadTypes, list)
+	}
+
+	var workloads []client.Object
 	for _, list := range workloadTypes {
 		if err := h.client.List(ctx, list); err != nil {
+			// Configured kinds may not be installed, or the operator may lack permissions on them. Neither
+			// should keep the other kinds from being restarted.
+			if _, ok := list.(*unstructured.UnstructuredList); ok {
+				message := "Skipping workload type that could not be listed"
+				if meta.IsNoMatchError(err) {
+					message = "Skipping workload type that is not installed in the cluster"
+				}
+				log.Error(err, message, "type", list.GetObjectKind().GroupVersionKind().String())
+				continue
+			}
 			log.Error(err, "Failed to list workloads", "type", fmt.Sprintf("%T", list))
-			return err
+			return nil, err
 		}
 
 		items, err := meta.ExtractList(list)
 		if err != nil {
 			log.Error(err, "Failed to extract list items", "type", fmt.Sprintf("%T", list))
-			return err
+			return nil, err
 		}
 
 		for _, obj := range items {
@@ -97,135 +269,304 @@ func (h *SecretUpdateHandler) restartWorkloadsWithUpdatedSecrets(
 				log.Error(fmt.Errorf("unexpected type %T", obj), "Skipping non-client.Object")
 				continue
 			}
-
-			podTemplate, err := getPodTemplate(workload)
-			if err != nil {
-				log.Error(err, "Failed to get pod template", "workload", workload.GetName())
-				continue
-			}
-
-			updatedSecrets := updatedSecretsByNamespace[workload.GetNamespace()]
-			if len(updatedSecrets) == 0 {
-				continue
-			}
-
-			matchedSecrets := getUpdatedSecretsForPodTemplate(workload.GetAnnotations(), podTemplate, updatedSecrets)
-			if len(matchedSecrets) == 0 {
+			if _, ok := workload.(*appsv1.ReplicaSet); ok && metav1.GetControllerOf(workload) != nil {
 				continue
 			}
-
-			for _, secret := range matchedSecrets {
-				if isSecretSetForAutoRestart(secret, workload, setForAutoRestartByNamespaceMap) {
-					if err := h.restartWorkload(ctx, workload); err != nil {
-						log.Error(err, "Failed to restart workload", "workload", workload.GetName(), "namespace", workload.GetNamespace())
-					}
-					break
-				}
-			}
-
-			log.V(logs.DebugLevel).Info(
-				fmt.Sprintf("%T %q at namespace %q is up to date", workload, workload.GetName(), workload.GetNamespace()),
-			)
+			workloads = append(workloads, workload)
 		}
 	}
-
-	return nil
+	return workloads, nil
 }
 
-func (h *SecretUpdateHandler) restartWorkload(ctx context.Context, workload client.Object) error {
-	podTemplate, err := getPodTemplate(workload)
-	if err != nil {
-		log.Error(err, "Unsupported workload type for restart", "type", fmt.Sprintf("%T", workload))
-		return err
-	}
-
+// restartWorkload restarts the workload to pick up changes to the secrets it uses, with the restart
+// strategy set by the annotation of the workload.
+func (h *SecretUpdateHandler) restartWorkload(ctx context.Context, workload client.Object, secrets ...*corev1.Secret) error {
+	strategy := restartStrategy(workload)
 	log.Info(
 		fmt.Sprintf(
-			"%T %q in namespace %q references an updated secret. Restarting",
+			"%T %q in namespace %q references an updated secret. Restarting with the %s strategy",
 			workload,
 			workload.GetName(),
 			workload.GetNamespace(),
+			strategy,
 		),
 	)
 
-	if podTemplate.Annotations == nil {
-		podTemplate.Annotations = map[string]string{}
+	var message string
+	var err error
+	switch strategy {
+	case RestartStrategyNotify:
+		return h.notifyRestartRequired(ctx, workload, secrets)
+	case RestartStrategyEvict:
+		var evicted int
+		evicted, err = h.evictPods(ctx, workload)
+		message = fmt.Sprintf("Evicted %d pods to pick up changes to %s", evicted, describeSecrets(secrets))
+	default:
+		err = h.updatePodTemplate(ctx, workload, strategy, secrets)
+		message = fmt.Sprintf("Restarted to pick up changes to %s", describeSecrets(secrets))
+		// ReplicaSets don't replace their pods when their template changes, so they are evicted to be
+		// recreated from the updated template.
+		if _, ok := workload.(*appsv1.ReplicaSet); ok && err == nil && strategy == RestartStrategyRolling {
+			var evicted int
+			evicted, err = h.evictPods(ctx, workload)
+			message = fmt.Sprintf("Evicted %d pods to pick up changes to %s", evicted, describeSecrets(secrets))
+		}
 	}
-	podTemplate.Annotations[RestartAnnotation] = time.Now().Format(time.RFC3339)
-
-	if err := h.client.Update(ctx, workload); err != nil {
-		log.Error(err, "Problem restarting workload", "name", workload.GetName())
+	if err != nil {
+		h.recordEvent(workload, corev1.EventTypeWarning, ReasonRestartFailed,
+			fmt.Sprintf("Failed to restart to pick up changes to %s: %s", describeSecrets(secrets), err))
 		return err
 	}
+	h.observeWorkloadRestart(workload)
+	h.recordRestart(workload)
+	h.recordEvent(workload, corev1.EventTypeNormal, ReasonWorkloadRestarted, message)
 	return nil
 }
 
+// observeWorkloadRestart counts the restart of workload by kind and namespace.
+func (h *SecretUpdateHandler) observeWorkloadRestart(workload client.Object) {
+	kind := fmt.Sprintf("%T", workload)
+	if gvk, err := apiutil.GVKForObject(workload, h.client.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+	workloadRestartsTotal.WithLabelValues(kind, workload.GetNamespace()).Inc()
+}
+
+// recordEvent records an event if the handler was created with an event recorder.
+func (h *SecretUpdateHandler) recordEvent(object runtime.Object, eventType, reason, message string) {
+	if h.recorder == nil {
+		return
+	}
+	h.recorder.Event(object, eventType, reason, message)
+}
+
+// recordSecretEvent records an event on the secret and on the objects owning it.
+func (h *SecretUpdateHandler) recordSecretEvent(secret *corev1.Secret, eventType, reason, message string) {
+	for _, target := range secretEventTargets(secret) {
+		h.recordEvent(target, eventType, reason, message)
+	}
+}
+
+// updateKubernetesSecrets updates the secrets whose items changed. It returns the updated secrets by
+// namespace and name, and the keys that changed in each of them.
 func (h *SecretUpdateHandler) updateKubernetesSecrets(ctx context.Context) (
-	map[string]map[string]*corev1.Secret, error,
+	map[string]map[string]*corev1.Secret, map[types.NamespacedName][]string, error,
 ) {
 	secrets := &corev1.SecretList{}
 	err := h.client.List(ctx, secrets)
 	if err != nil {
 		log.Error(err, "Failed to list kubernetes secrets")
-		return nil, err
+		return nil, nil, err
 	}
 
-	updatedSecrets := map[string]map[string]*corev1.Secret{}
-	for i := 0; i < len(secrets.Items); i++ {
-		secret := secrets.Items[i]
+	// Syncs of created or changed resources are served first when requests are rate limited.
+	ctx = ratelimit.WithPriority(ctx, ratelimit.Background)
+	// A run must not outlast the polling interval, so a slow backend cannot stall polling.
+	tickCtx, cancel := context.WithTimeout(ctx, h.pollingInterval())
+	defer cancel()
+	// A transient error cancels the run: the remaining secrets would fail the same way,
+	// so they are left for the next run.
+	group, groupCtx := errgroup.WithContext(tickCtx)
+	group.SetLimit(h.pollingConcurrency())
+	items := newItemFetcher(h.opClient, h.itemTimeout())
+	if h.config.BatchByVault {
+		items = newBatchItemFetcher(h.opClient, h.itemTimeout(), h.knownItems)
+	}
 
-		itemPath := secret.Annotations[ItemPathAnnotation]
-		currentVersion := secret.Annotations[VersionAnnotation]
-		if len(itemPath) == 0 || len(currentVersion) == 0 {
+	var mu sync.Mutex
+	updatedSecrets := map[string]map[string]*corev1.Secret{}
+	changedKeys := map[types.NamespacedName][]string{}
+	synced := map[types.NamespacedName]bool{}
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if len(secret.Annotations[ItemPathAnnotation]) == 0 || len(secret.Annotations[VersionAnnotation]) == 0 {
 			continue
 		}
-
-		OnePasswordItemPath := h.getPathFromOnePasswordItem(secret)
-
-		item, err := GetOnePasswordItemByPath(ctx, h.opClient, OnePasswordItemPath)
-		if err != nil {
-			log.Error(err, fmt.Sprintf("failed to retrieve 1Password item at path %s for secret %s",
-				secret.Annotations[ItemPathAnnotation], secret.Name,
-			))
-			continue
+		synced[client.ObjectKeyFromObject(secret)] = true
+		if groupCtx.Err() != nil {
+			break
 		}
 
-		itemVersion := fmt.Sprint(item.Version)
-		itemPathString := fmt.Sprintf("vaults/%v/items/%v", item.VaultID, item.ID)
-
-		if currentVersion != itemVersion || secret.Annotations[ItemPathAnnotation] != itemPathString {
-			if isItemLockedForForcedRestarts(item) {
-				log.V(logs.DebugLevel).Info(fmt.Sprintf(
-					"Secret '%v' has been updated in 1Password but is set to be ignored. "+
-						"Updates to an ignored secret will not trigger an update to a kubernetes secret or a rolling restart.",
-					secret.GetName(),
-				))
-				secret.Annotations[VersionAnnotation] = itemVersion
-				secret.Annotations[ItemPathAnnotation] = itemPathString
-				if err := h.client.Update(ctx, &secret); err != nil {
-					log.Error(err, fmt.Sprintf("failed to update secret %s annotations to version %s", secret.Name, itemVersion))
-					continue
-				}
-				continue
-			}
-			log.Info(fmt.Sprintf("Updating kubernetes secret '%v'", secret.GetName()))
-			secret.Annotations[VersionAnnotation] = itemVersion
-			secret.Annotations[ItemPathAnnotation] = itemPathString
-			secret.Data = kubeSecrets.BuildKubernetesSecretData(item.Fields, item.URLs, item.Files, h.config.AllowEmptyValues)
-			log.V(logs.DebugLevel).Info(fmt.Sprintf("New secret path: %v and version: %v",
-				secret.Annotations[ItemPathAnnotation], secret.Annotations[VersionAnnotation],
-			))
-			if err := h.client.Update(ctx, &secret); err != nil {
-				log.Error(err, fmt.Sprintf("failed to update secret %s to version %s", secret.Name, itemVersion))
-				continue
+		group.Go(func() error {
+			ctx, span := tracing.Start(groupCtx, "SecretUpdateHandler.updateKubernetesSecret",
+				attribute.String("k8s.namespace.name", secret.Namespace), attribute.String("k8s.secret.name", secret.Name))
+			// Running pods may use data the secret drifted from rather than its current data.
+			previousData, drifted := secret.Data, kubeSecrets.IsDrifted(secret)
+			updated, err := h.updateKubernetesSecret(ctx, secret, items)
+			span.SetAttributes(attribute.Bool("onepassword.updated", updated))
+			tracing.End(span, err)
+			if err != nil || !updated {
+				return err
 			}
+			mu.Lock()
+			defer mu.Unlock()
 			if updatedSecrets[secret.Namespace] == nil {
 				updatedSecrets[secret.Namespace] = make(map[string]*corev1.Secret)
 			}
-			updatedSecrets[secret.Namespace][secret.Name] = &secret
+			updatedSecrets[secret.Namespace][secret.Name] = secret
+			if !drifted {
+				changedKeys[client.ObjectKeyFromObject(secret)] = changedSecretKeys(previousData, secret.Data)
+			}
+			return nil
+		})
+	}
+
+	transientErr := group.Wait()
+	lastSyncs.retain(synced)
+	if h.config.BatchByVault {
+		h.knownItems = items.known(transientErr == nil && tickCtx.Err() == nil)
+	}
+	if transientErr != nil {
+		h.backOff(transientErr)
+	} else if tickCtx.Err() != nil && ctx.Err() == nil {
+		log.Info(fmt.Sprintf("Updating kubernetes secrets did not complete within %s, "+
+			"the remaining secrets will be updated next run", h.pollingInterval()))
+	} else {
+		h.failures = 0
+	}
+	return updatedSecrets, changedKeys, nil
+}
+
+// updateKubernetesSecret updates secret to the latest version of its item. It reports whether the
+// item changed, in which case workloads using the secret need a restart. Only transient errors are
+// returned, other errors are logged and the secret is retried next run.
+func (h *SecretUpdateHandler) updateKubernetesSecret(
+	ctx context.Context,
+	secret *corev1.Secret,
+	items *itemFetcher,
+) (bool, error) {
+	currentVersion := secret.Annotations[VersionAnnotation]
+	onePasswordItem := h.getOnePasswordItem(ctx, *secret)
+	OnePasswordItemPath := secret.Annotations[ItemPathAnnotation]
+	if onePasswordItem != nil {
+		OnePasswordItemPath = onePasswordItem.Spec.ItemPath
+	}
+
+	item, err := items.get(ctx, OnePasswordItemPath)
+	if err != nil {
+		// The run was cancelled, the error is about another secret.
+		if ctx.Err() != nil && !opclient.IsTransient(err) {
+			return false, nil
+		}
+		log.Error(err, fmt.Sprintf("failed to retrieve 1Password item at path %s for secret %s",
+			secret.Annotations[ItemPathAnnotation], secret.Name,
+		))
+		ObserveSecretSyncFailed(secret.Namespace)
+		if errors.Is(err, opclient.ErrNotFound) {
+			h.recordSecretEvent(secret, corev1.EventTypeWarning, ReasonItemNotFound, err.Error())
+		}
+		if opclient.IsTransient(err) {
+			return false, err
 		}
+		return false, nil
+	}
+
+	keystore, err := h.getKeystore(ctx, onePasswordItem, items.get)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("failed to retrieve keystore items for secret %s", secret.Name))
+		ObserveSecretSyncFailed(secret.Namespace)
+		if opclient.IsTransient(err) {
+			return false, err
+		}
+		return false, nil
+	}
+	keystoreChecksum := ""
+	if keystore != nil {
+		keystoreChecksum = keystore.Checksum()
+	}
+
+	itemVersion := fmt.Sprint(item.Version)
+	itemPathString := fmt.Sprintf("vaults/%v/items/%v", item.VaultID, item.ID)
+
+	itemChanged := currentVersion != itemVersion || secret.Annotations[ItemPathAnnotation] != itemPathString ||
+		secret.Annotations[kubeSecrets.KeystoreChecksumAnnotation] != keystoreChecksum
+	drifted := kubeSecrets.IsDrifted(secret)
+	if !itemChanged && !drifted {
+		ObserveSecretSynced(secret.Namespace, secret.Name, item)
+		return false, nil
+	}
+
+	if itemChanged && isItemLockedForForcedRestarts(item) {
+		log.V(logs.DebugLevel).Info(fmt.Sprintf(
+			"Secret '%v' has been updated in 1Password but is set to be ignored. "+
+				"Updates to an ignored secret will not trigger an update to a kubernetes secret or a rolling restart.",
+			secret.GetName(),
+		))
+		secret.Annotations[VersionAnnotation] = itemVersion
+		secret.Annotations[ItemPathAnnotation] = itemPathString
+		if keystoreChecksum != "" {
+			secret.Annotations[kubeSecrets.KeystoreChecksumAnnotation] = keystoreChecksum
+		} else {
+			delete(secret.Annotations, kubeSecrets.KeystoreChecksumAnnotation)
+		}
+		if err := h.client.Update(ctx, secret, client.FieldOwner(kubeSecrets.FieldManager)); err != nil {
+			log.Error(err, fmt.Sprintf("failed to update secret %s annotations to version %s", secret.Name, itemVersion))
+			ObserveSecretSyncFailed(secret.Namespace)
+			return false, nil
+		}
+		ignoredItemUpdatesTotal.WithLabelValues(secret.Namespace).Inc()
+		ObserveSecretSynced(secret.Namespace, secret.Name, item)
+		h.recordSecretEvent(secret, corev1.EventTypeNormal, ReasonUpdateIgnoredByTag,
+			fmt.Sprintf("Item version %s was not applied because the item is tagged %q", itemVersion, lockTag))
+		return false, nil
+	}
+	changedBy := ""
+	if drifted {
+		changedBy = kubeSecrets.LastChangedBy(secret)
+		log.Info(fmt.Sprintf("Kubernetes secret '%v' was changed by %v, restoring it",
+			secret.GetName(), changedBy,
+		))
+	}
+
+	// Files are only downloaded now that the secret needs to be rebuilt.
+	item, err = items.getWithFiles(ctx, OnePasswordItemPath)
+	if err == nil {
+		keystore, err = h.getKeystore(ctx, onePasswordItem, items.getWithFiles)
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("failed to retrieve files for secret %s", secret.Name))
+		ObserveSecretSyncFailed(secret.Namespace)
+		if opclient.IsTransient(err) {
+			return false, err
+		}
+		return false, nil
+	}
+
+	log.Info(fmt.Sprintf("Updating kubernetes secret '%v'", secret.GetName()))
+	secret.Annotations[VersionAnnotation] = itemVersion
+	secret.Annotations[ItemPathAnnotation] = itemPathString
+	secret.Data = kubeSecrets.BuildKubernetesSecretDataForItem(*item, h.config.AllowEmptyValues,
+		kubeSecrets.IsCategoryPresetEnabled(secret.Annotations))
+	if err := kubeSecrets.AddKeystoreData(secret.Data, secret.Annotations, *item, keystore); err != nil {
+		log.Error(err, fmt.Sprintf("failed to build keystore for secret %s", secret.Name))
+		ObserveSecretSyncFailed(secret.Namespace)
+		return false, nil
+	}
+	secret.Annotations[kubeSecrets.ContentHashAnnotation] = kubeSecrets.ContentHash(secret.Data)
+	log.V(logs.DebugLevel).Info(fmt.Sprintf("New secret path: %v and version: %v",
+		secret.Annotations[ItemPathAnnotation], secret.Annotations[VersionAnnotation],
+	))
+	if err := h.client.Update(ctx, secret, client.FieldOwner(kubeSecrets.FieldManager)); err != nil {
+		log.Error(err, fmt.Sprintf("failed to update secret %s to version %s", secret.Name, itemVersion))
+		ObserveSecretSyncFailed(secret.Namespace)
+		return false, nil
+	}
+	ObserveSecretSynced(secret.Namespace, secret.Name, item)
+	if drifted {
+		h.recordSecretEvent(secret, corev1.EventTypeWarning, ReasonSecretDrifted,
+			fmt.Sprintf("Secret data was modified by %q, restored it from 1Password", changedBy))
+	}
+	// Restoring drifted data brings the secret back to what running pods were started with.
+	if !itemChanged {
+		return false, nil
+	}
+	h.recordSecretEvent(secret, corev1.EventTypeNormal, ReasonSynced,
+		fmt.Sprintf("Updated to item version %s", itemVersion))
+	for _, skipped := range kubeSecrets.SkippedItemFields(*item, h.config.AllowEmptyValues,
+		kubeSecrets.IsCategoryPresetEnabled(secret.Annotations)) {
+		h.recordSecretEvent(secret, corev1.EventTypeWarning, ReasonFieldSkipped, skipped)
 	}
-	return updatedSecrets, nil
+	return true, nil
 }
 
 func isItemLockedForForcedRestarts(item *model.Item) bool {
@@ -282,20 +623,40 @@ func (h *SecretUpdateHandler) getIsSetForAutoRestartByNamespaceMap(
 	return namespacesMap, nil
 }
 
-func (h *SecretUpdateHandler) getPathFromOnePasswordItem(secret corev1.Secret) string {
+// getOnePasswordItem returns the OnePasswordItem the secret was created from, or nil if
+// the secret was created from annotations on a workload instead.
+func (h *SecretUpdateHandler) getOnePasswordItem(ctx context.Context, secret corev1.Secret) *onepasswordv1.OnePasswordItem {
 	onePasswordItem := &onepasswordv1.OnePasswordItem{}
 
 	// Search for our original OnePasswordItem if it exists
-	err := h.client.Get(context.TODO(), client.ObjectKey{
+	err := h.client.Get(ctx, client.ObjectKey{
 		Namespace: secret.Namespace,
 		Name:      secret.Name}, onePasswordItem)
+	if err != nil {
+		return nil
+	}
+	return onePasswordItem
+}
 
-	if err == nil {
-		return onePasswordItem.Spec.ItemPath
+// getKeystore returns the keystore bundles configured on the OnePasswordItem, if any.
+func (h *SecretUpdateHandler) getKeystore(
+	ctx context.Context,
+	onePasswordItem *onepasswordv1.OnePasswordItem,
+	getItem func(ctx context.Context, path string) (*model.Item, error),
+) (*kubeSecrets.Keystore, error) {
+	if onePasswordItem == nil || onePasswordItem.Spec.Keystore == nil {
+		return nil, nil
 	}
 
-	// If we can't find the OnePassword Item we'll just return the annotation from the secret item.
-	return secret.Annotations[ItemPathAnnotation]
+	keystoreItems := make([]model.Item, 0, len(onePasswordItem.Spec.Keystore.ItemPaths))
+	for _, path := range onePasswordItem.Spec.Keystore.ItemPaths {
+		item, err := getItem(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve keystore item %q: %w", path, err)
+		}
+		keystoreItems = append(keystoreItems, *item)
+	}
+	return &kubeSecrets.Keystore{Spec: onePasswordItem.Spec.Keystore, Items: keystoreItems}, nil
 }
 
 func isSecretSetForAutoRestart(
@@ -347,6 +708,24 @@ func isWorkloadSetForAutoRestart(workload client.Object, setForAutoRestartByName
 	return restartBool
 }
 
+// shouldSkipRestartsForLiveMounts reports whether the workload is left running when it only uses updated
+// secrets through live mounts. The restart-live-mounts annotation of the workload overrides the global setting.
+func (h *SecretUpdateHandler) shouldSkipRestartsForLiveMounts(workload client.Object) bool {
+	restartLiveMounts, found := workload.GetAnnotations()[RestartLiveMountsAnnotation]
+	if !found {
+		return h.config.SkipRestartsForLiveMounts
+	}
+	restart, err := utils.StringToBool(restartLiveMounts)
+	if err != nil {
+		log.Error(err, fmt.Sprintf(
+			"Error parsing %s annotation on %T %s. Must be true or false. Defaulting to true.",
+			RestartLiveMountsAnnotation, workload, workload.GetName(),
+		))
+		return false
+	}
+	return !restart
+}
+
 func (h *SecretUpdateHandler) isNamespaceSetToAutoRestart(namespace *corev1.Namespace) bool {
 	restartWorkload := namespace.Annotations[AutoRestartWorkloadAnnotation]
 	// If annotation for auto restarts for workload is not set. Check environment variable set on the operator
@@ -364,15 +743,70 @@ func (h *SecretUpdateHandler) isNamespaceSetToAutoRestart(namespace *corev1.Name
 	return restartWorkloadBool
 }
 
-func getPodTemplate(obj client.Object) (*corev1.PodTemplateSpec, error) {
+// getPodTemplate returns the pod template of a workload. For unstructured workloads the
+// template is a copy, use setPodTemplateAnnotation to change it.
+func (h *SecretUpdateHandler) getPodTemplate(obj client.Object) (*corev1.PodTemplateSpec, error) {
 	switch o := obj.(type) {
 	case *appsv1.Deployment:
 		return &o.Spec.Template, nil
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template, nil
+	case *appsv1.DaemonSet:
+		return &o.Spec.Template, nil
+	case *appsv1.ReplicaSet:
+		return &o.Spec.Template, nil
+	case *unstructured.Unstructured:
+		path, err := h.getPodTemplatePath(o)
+		if err != nil {
+			return nil, err
+		}
+		fields, found, err := unstructured.NestedMap(o.Object, path...)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, fmt.Errorf("pod template not found at %s in %s %q", strings.Join(path, "."), o.GetKind(), o.GetName())
+		}
+		podTemplate := &corev1.PodTemplateSpec{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fields, podTemplate); err != nil {
+			return nil, err
+		}
+		return podTemplate, nil
 	default:
 		return nil, fmt.Errorf("unsupported type %T", obj)
 	}
 }
 
+func (h *SecretUpdateHandler) setPodTemplateAnnotation(obj client.Object, key, value string) error {
+	if o, ok := obj.(*unstructured.Unstructured); ok {
+		path, err := h.getPodTemplatePath(o)
+		if err != nil {
+			return err
+		}
+		return unstructured.SetNestedField(o.Object, value, append(slices.Clone(path), "metadata", "annotations", key)...)
+	}
+
+	podTemplate, err := h.getPodTemplate(obj)
+	if err != nil {
+		return err
+	}
+	if podTemplate.Annotations == nil {
+		podTemplate.Annotations = map[string]string{}
+	}
+	podTemplate.Annotations[key] = value
+	return nil
+}
+
+func (h *SecretUpdateHandler) getPodTemplatePath(obj *unstructured.Unstructured) ([]string, error) {
+	gvk := obj.GroupVersionKind()
+	for _, workloadType := range h.config.ExtraWorkloadTypes {
+		if workloadType.GroupVersionKind == gvk {
+			return workloadType.PodTemplatePath, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported workload type %s", gvk.String())
+}
+
 func getUpdatedSecretsForPodTemplate(
 	annotations map[string]string,
 	podTemplate *corev1.PodTemplateSpec,
@@ -9,18 +9,26 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 
+	kubeSecrets "github.com/1Password/onepassword-operator/pkg/kubernetessecrets"
 	"github.com/1Password/onepassword-operator/pkg/mocks"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/client/retry"
 	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	errors2 "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/kubectl/pkg/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
 )
 
 const (
@@ -54,6 +62,10 @@ var (
 		"password": []byte(password),
 		"username": []byte(username),
 	}
+	outdatedSecretData = map[string][]byte{
+		"password": []byte("old password"),
+		"username": []byte(username),
+	}
 	itemPath = fmt.Sprintf("vaults/%v/items/%v", vaultId, itemId)
 )
 
@@ -93,7 +105,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -153,6 +165,133 @@ var tests = []testUpdateSecretTask{
 				},
 			},
 		},
+		existingSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  "old version",
+					ItemPathAnnotation: itemPath,
+				},
+			},
+			Data: outdatedSecretData,
+		},
+		expectedError: nil,
+		expectedResultSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  fmt.Sprint(itemVersion),
+					ItemPathAnnotation: itemPath,
+				},
+			},
+			Data: expectedSecretData,
+		},
+		opItem: map[string]string{
+			userKey: username,
+			passKey: password,
+		},
+		expectedRestart:          true,
+		globalAutoRestartEnabled: true,
+	},
+	{
+		testName:          "OP item has new version. Secret needs update. Deployment using only unchanged keys is not restarted",
+		existingNamespace: defaultNamespace,
+		existingWorkload: &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       deploymentKind,
+				APIVersion: deploymentAPIVersion,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Env: []corev1.EnvVar{
+									{
+										Name: name,
+										ValueFrom: &corev1.EnvVarSource{
+											SecretKeyRef: &corev1.SecretKeySelector{
+												LocalObjectReference: corev1.LocalObjectReference{
+													Name: name,
+												},
+												Key: userKey,
+											},
+										},
+									},
+								},
+							},
+						},
+						Volumes: []corev1.Volume{
+							{
+								Name: name,
+								VolumeSource: corev1.VolumeSource{
+									Secret: &corev1.SecretVolumeSource{
+										SecretName: name,
+										Items:      []corev1.KeyToPath{{Key: userKey, Path: userKey}},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		existingSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  "old version",
+					ItemPathAnnotation: itemPath,
+				},
+			},
+			Data: outdatedSecretData,
+		},
+		expectedError: nil,
+		expectedResultSecret: &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  fmt.Sprint(itemVersion),
+					ItemPathAnnotation: itemPath,
+				},
+			},
+			Data: expectedSecretData,
+		},
+		opItem: map[string]string{
+			userKey: username,
+			passKey: password,
+		},
+		expectedRestart:          false,
+		globalAutoRestartEnabled: true,
+	},
+	{
+		testName:          "OP item has new version without data changes. Deployment using the whole secret is restarted",
+		existingNamespace: defaultNamespace,
+		existingWorkload: &appsv1.Deployment{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       deploymentKind,
+				APIVersion: deploymentAPIVersion,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: appsv1.DeploymentSpec{
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: generateContainersWithSecretRefsFromEnvFrom([]string{name}),
+					},
+				},
+			},
+		},
 		existingSecret: &corev1.Secret{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      name,
@@ -209,7 +348,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -271,7 +410,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -388,7 +527,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -461,7 +600,7 @@ var tests = []testUpdateSecretTask{
 					AutoRestartWorkloadAnnotation: "true",
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -535,7 +674,7 @@ var tests = []testUpdateSecretTask{
 					AutoRestartWorkloadAnnotation: "false",
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -608,7 +747,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -688,7 +827,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -764,7 +903,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -837,7 +976,7 @@ var tests = []testUpdateSecretTask{
 					ItemPathAnnotation: itemPath,
 				},
 			},
-			Data: expectedSecretData,
+			Data: outdatedSecretData,
 		},
 		expectedError: nil,
 		expectedResultSecret: &corev1.Secret{
@@ -895,6 +1034,7 @@ func TestUpdateSecretHandler(t *testing.T) {
 			}
 
 			err := h.UpdateKubernetesSecretsTask(ctx)
+			h.processRestarts(ctx)
 
 			assert.Equal(t, testData.expectedError, err)
 
@@ -1209,6 +1349,504 @@ func TestUpdateSecretHandlerAllowEmptyValues(t *testing.T) {
 	}
 }
 
+func TestRestartWorkloadsWithUpdatedSecretsForWorkloadKinds(t *testing.T) {
+	previousInterval := rolloutCheckInterval
+	rolloutCheckInterval = 10 * time.Millisecond
+	defer func() { rolloutCheckInterval = previousInterval }()
+
+	ctx := context.Background()
+	secretName := "updated-secret"
+	containers := generateContainersWithSecretRefsFromEnv([]string{secretName})
+	podTemplate := corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}}
+
+	rolloutGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	s := runtime.NewScheme()
+	assert.NoError(t, clientgoscheme.AddToScheme(s))
+	s.AddKnownTypeWithName(rolloutGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(rolloutGVK.GroupVersion().WithKind("RolloutList"), &unstructured.UnstructuredList{})
+
+	rolloutTemplate, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podTemplate)
+	assert.NoError(t, err)
+	rollout := &unstructured.Unstructured{Object: map[string]any{
+		"spec": map[string]any{"template": rolloutTemplate},
+	}}
+	rollout.SetGroupVersionKind(rolloutGVK)
+	rollout.SetName("rollout")
+	rollout.SetNamespace(namespace)
+
+	isController := true
+	objs := []client.Object{
+		defaultNamespace,
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "statefulset", Namespace: namespace},
+			Spec:       appsv1.StatefulSetSpec{Template: podTemplate},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "daemonset", Namespace: namespace},
+			Spec:       appsv1.DaemonSetSpec{Template: podTemplate},
+		},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "replicaset", Namespace: namespace},
+			Spec: appsv1.ReplicaSetSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "replicaset"}},
+				Template: podTemplate,
+			},
+			Status: appsv1.ReplicaSetStatus{AvailableReplicas: 1, ObservedGeneration: 10},
+		},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "replicaset-pod",
+			Namespace: namespace,
+			Labels:    map[string]string{"app": "replicaset"},
+		}},
+		&appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "owned-replicaset",
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{
+					{APIVersion: "apps/v1", Kind: "Deployment", Name: "deployment", UID: "uid", Controller: &isController},
+				},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: podTemplate},
+		},
+		rollout,
+	}
+	cl := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		config: SecretUpdateHandlerConfig{
+			ShouldAutoRestartWorkloadsGlobally: true,
+			ExtraWorkloadTypes: []WorkloadType{
+				{GroupVersionKind: rolloutGVK, PodTemplatePath: []string{"spec", "template"}},
+			},
+		},
+	}
+
+	updatedSecrets := map[string]map[string]*corev1.Secret{
+		namespace: {secretName: {ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}},
+	}
+	assert.NoError(t, h.restartWorkloadsWithUpdatedSecrets(ctx, updatedSecrets, nil))
+	h.processRestarts(ctx)
+
+	statefulSet := &appsv1.StatefulSet{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "statefulset", Namespace: namespace}, statefulSet))
+	assert.Contains(t, statefulSet.Spec.Template.Annotations, RestartAnnotation)
+
+	daemonSet := &appsv1.DaemonSet{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "daemonset", Namespace: namespace}, daemonSet))
+	assert.Contains(t, daemonSet.Spec.Template.Annotations, RestartAnnotation)
+
+	replicaSet := &appsv1.ReplicaSet{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "replicaset", Namespace: namespace}, replicaSet))
+	assert.Contains(t, replicaSet.Spec.Template.Annotations, RestartAnnotation)
+	replicaSetPod := &corev1.Pod{}
+	assert.True(t, errors2.IsNotFound(cl.Get(ctx, types.NamespacedName{Name: "replicaset-pod", Namespace: namespace}, replicaSetPod)),
+		"Expected the pods of the ReplicaSet to be replaced")
+
+	ownedReplicaSet := &appsv1.ReplicaSet{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "owned-replicaset", Namespace: namespace}, ownedReplicaSet))
+	assert.NotContains(t, ownedReplicaSet.Spec.Template.Annotations, RestartAnnotation,
+		"ReplicaSets owned by a Deployment should not be restarted directly")
+
+	updatedRollout := &unstructured.Unstructured{}
+	updatedRollout.SetGroupVersionKind(rolloutGVK)
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "rollout", Namespace: namespace}, updatedRollout))
+	_, found, err := unstructured.NestedString(updatedRollout.Object,
+		"spec", "template", "metadata", "annotations", RestartAnnotation)
+	assert.NoError(t, err)
+	assert.True(t, found, "Expected rollout to restart but it did not")
+}
+
+func TestUpdateSecretHandlerRestoresDriftedSecret(t *testing.T) {
+	ctx := context.Background()
+	secretName := "drifted-secret"
+	expectedData := map[string][]byte{
+		"username": []byte(username),
+		"password": []byte(password),
+	}
+
+	existingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				VersionAnnotation:                 fmt.Sprint(itemVersion),
+				ItemPathAnnotation:                itemPath,
+				kubeSecrets.ContentHashAnnotation: kubeSecrets.ContentHash(expectedData),
+			},
+		},
+		Data: map[string][]byte{
+			"username": []byte(username),
+			"password": []byte("changed"),
+		},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: namespace},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: generateContainersWithSecretRefsFromEnv([]string{secretName})},
+			},
+		},
+	}
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithRuntimeObjects(defaultNamespace, existingSecret, deployment).Build()
+
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(createItem(), nil)
+	mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		opClient:  mockOpClient,
+		recorder:  recorder,
+		config: SecretUpdateHandlerConfig{
+			ShouldAutoRestartWorkloadsGlobally: true,
+		},
+	}
+
+	assert.NoError(t, h.UpdateKubernetesSecretsTask(ctx))
+	assert.Equal(t, []string{
+		fmt.Sprintf("Warning %s Secret data was modified by \"unknown\", restored it from 1Password", ReasonSecretDrifted),
+	}, drainEvents(recorder))
+
+	restoredSecret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, restoredSecret))
+	assert.Equal(t, expectedData, restoredSecret.Data)
+	assert.False(t, kubeSecrets.IsDrifted(restoredSecret))
+
+	updatedDeployment := &appsv1.Deployment{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "deployment", Namespace: namespace}, updatedDeployment))
+	assert.NotContains(t, updatedDeployment.Spec.Template.Annotations, RestartAnnotation,
+		"Restoring a drifted secret should not restart workloads")
+}
+
+func TestUpdateSecretHandlerBacksOffWhenRateLimited(t *testing.T) {
+	ctx := context.Background()
+	existingSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "rate-limited-secret",
+			Namespace: namespace,
+			Annotations: map[string]string{
+				VersionAnnotation:  fmt.Sprint(itemVersion - 1),
+				ItemPathAnnotation: itemPath,
+			},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithRuntimeObjects(defaultNamespace, existingSecret).Build()
+
+	rateLimitErr := &opclient.RateLimitError{RetryAfter: time.Minute, Err: fmt.Errorf("too many requests")}
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(nil, rateLimitErr).Once()
+	mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(createItem(), nil)
+	mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		opClient:  mockOpClient,
+		config: SecretUpdateHandlerConfig{
+			RetryPolicy: retry.Policy{InitialInterval: time.Second, MaxInterval: time.Hour, Multiplier: 2},
+		},
+	}
+
+	assert.NoError(t, h.UpdateKubernetesSecretsTask(ctx))
+	assert.Equal(t, 1, h.failures)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), h.retryAt, 5*time.Second,
+		"The Retry-After hint should decide when polling resumes")
+
+	assert.NoError(t, h.UpdateKubernetesSecretsTask(ctx))
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 1)
+
+	h.retryAt = time.Time{}
+	assert.NoError(t, h.UpdateKubernetesSecretsTask(ctx))
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 2)
+	assert.Equal(t, 0, h.failures)
+
+	updatedSecret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "rate-limited-secret", Namespace: namespace}, updatedSecret))
+	assert.Equal(t, fmt.Sprint(itemVersion), updatedSecret.Annotations[VersionAnnotation])
+}
+
+func TestUpdateSecretHandlerFetchesSharedItemOnce(t *testing.T) {
+	ctx := context.Background()
+	var objects []runtime.Object
+	for i := 0; i < 10; i++ {
+		objects = append(objects, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      fmt.Sprintf("shared-secret-%d", i),
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  fmt.Sprint(itemVersion - 1),
+					ItemPathAnnotation: itemPath,
+				},
+			},
+		})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithRuntimeObjects(append(objects, defaultNamespace)...).Build()
+
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(createItem(), nil)
+	mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		opClient:  mockOpClient,
+		config:    SecretUpdateHandlerConfig{PollingConcurrency: 3},
+	}
+
+	updatedSecrets, _, err := h.updateKubernetesSecrets(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, updatedSecrets[namespace], 10)
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 1)
+
+	for i := 0; i < 10; i++ {
+		secret := &corev1.Secret{}
+		assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: fmt.Sprintf("shared-secret-%d", i), Namespace: namespace}, secret))
+		assert.Equal(t, fmt.Sprint(itemVersion), secret.Annotations[VersionAnnotation])
+	}
+}
+
+func TestUpdateSecretHandlerDownloadsFilesOnlyForChangedItems(t *testing.T) {
+	testCases := map[string]struct {
+		secretVersion      int
+		expectedDownloads  int
+		expectedSecretData map[string][]byte
+	}{
+		"version unchanged": {
+			secretVersion:      itemVersion,
+			expectedDownloads:  0,
+			expectedSecretData: map[string][]byte{"password": []byte("old")},
+		},
+		"version changed": {
+			secretVersion:     itemVersion - 1,
+			expectedDownloads: 1,
+			expectedSecretData: map[string][]byte{
+				"username":        []byte(username),
+				"password":        []byte(password),
+				"certificate.pem": []byte("file content"),
+			},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			ctx := context.Background()
+			objects := []runtime.Object{defaultNamespace}
+			for _, name := range []string{"first-secret", "second-secret"} {
+				objects = append(objects, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      name,
+						Namespace: namespace,
+						Annotations: map[string]string{
+							VersionAnnotation:  fmt.Sprint(tc.secretVersion),
+							ItemPathAnnotation: itemPath,
+						},
+					},
+					Data: map[string][]byte{"password": []byte("old")},
+				})
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(objects...).Build()
+
+			item := createItem()
+			item.Files = []model.File{{ID: "file-id", Name: "certificate.pem"}}
+			mockOpClient := &mocks.TestClient{}
+			mockOpClient.On("GetItemByID", vaultId, itemId).Return(item, nil)
+			mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+			mockOpClient.On("GetFileContent", vaultId, itemId, "file-id").Return([]byte("file content"), nil)
+
+			h := &SecretUpdateHandler{client: cl, apiReader: cl, opClient: mockOpClient}
+			_, _, err := h.updateKubernetesSecrets(ctx)
+			assert.NoError(t, err)
+			mockOpClient.AssertNumberOfCalls(t, "GetFileContent", tc.expectedDownloads)
+
+			for _, name := range []string{"first-secret", "second-secret"} {
+				secret := &corev1.Secret{}
+				assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, secret))
+				assert.Equal(t, tc.expectedSecretData, secret.Data)
+			}
+		})
+	}
+}
+
+func TestUpdateSecretHandlerBatchByVault(t *testing.T) {
+	ctx := context.Background()
+	objects := []runtime.Object{defaultNamespace}
+	for _, name := range []string{"first-secret", "second-secret"} {
+		objects = append(objects, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Annotations: map[string]string{
+					VersionAnnotation:  fmt.Sprint(itemVersion),
+					ItemPathAnnotation: itemPath,
+				},
+			},
+		})
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(objects...).Build()
+
+	updatedItem := createItem()
+	updatedItem.Version = itemVersion + 1
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+	mockOpClient.On("ListItems", vaultId).Return([]model.Item{
+		{ID: itemId, VaultID: vaultId, Version: itemVersion},
+		{ID: "otheritem", VaultID: vaultId, Version: 1},
+	}, nil).Twice()
+	mockOpClient.On("ListItems", vaultId).Return([]model.Item{
+		{ID: itemId, VaultID: vaultId, Version: itemVersion + 1},
+		{ID: "otheritem", VaultID: vaultId, Version: 1},
+	}, nil)
+	mockOpClient.On("GetItemByID", vaultId, itemId).Return(createItem(), nil).Once()
+	mockOpClient.On("GetItemByID", vaultId, itemId).Return(updatedItem, nil)
+
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		opClient:  mockOpClient,
+		config:    SecretUpdateHandlerConfig{BatchByVault: true},
+	}
+
+	updatedSecrets, _, err := h.updateKubernetesSecrets(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, updatedSecrets)
+	mockOpClient.AssertNumberOfCalls(t, "ListItems", 1)
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 1)
+
+	// The listing shows no change, so the item is not fetched again.
+	updatedSecrets, _, err = h.updateKubernetesSecrets(ctx)
+	assert.NoError(t, err)
+	assert.Empty(t, updatedSecrets)
+	mockOpClient.AssertNumberOfCalls(t, "ListItems", 2)
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 1)
+
+	updatedSecrets, _, err = h.updateKubernetesSecrets(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, updatedSecrets[namespace], 2)
+	mockOpClient.AssertNumberOfCalls(t, "ListItems", 3)
+	mockOpClient.AssertNumberOfCalls(t, "GetItemByID", 2)
+
+	secret := &corev1.Secret{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "first-secret", Namespace: namespace}, secret))
+	assert.Equal(t, fmt.Sprint(itemVersion+1), secret.Annotations[VersionAnnotation])
+}
+
+func TestUpdateSecretHandlerEvents(t *testing.T) {
+	ownerRef := metav1.OwnerReference{APIVersion: "onepassword.com/v1", Kind: "OnePasswordItem", Name: "item", UID: "item-uid"}
+	testCases := map[string]struct {
+		item           *model.Item
+		itemErr        error
+		expectedEvents []string
+	}{
+		"synced": {
+			item: &model.Item{
+				ID:      itemId,
+				VaultID: vaultId,
+				Version: itemVersion + 1,
+				Fields:  []model.ItemField{{Label: "password", Value: password}, {Label: "empty", Value: ""}},
+			},
+			expectedEvents: []string{
+				fmt.Sprintf("Normal %s Updated to item version %d", ReasonSynced, itemVersion+1),
+				fmt.Sprintf("Warning %s Skipping field with empty value for label \"empty\" "+
+					"(use --allow-empty-values flag to include)", ReasonFieldSkipped),
+			},
+		},
+		"ignored by tag": {
+			item: &model.Item{
+				ID:      itemId,
+				VaultID: vaultId,
+				Version: itemVersion + 1,
+				Tags:    []string{lockTag},
+			},
+			expectedEvents: []string{
+				fmt.Sprintf("Normal %s Item version %d was not applied because the item is tagged %q",
+					ReasonUpdateIgnoredByTag, itemVersion+1, lockTag),
+			},
+		},
+		"item not found": {
+			itemErr: opclient.ErrNotFound,
+			expectedEvents: []string{
+				fmt.Sprintf("Warning %s failed to get item by ID for vaultID='%s' and itemID='%s': %s",
+					ReasonItemNotFound, vaultId, itemId, opclient.ErrNotFound),
+			},
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			ctx := context.Background()
+			existingSecret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            "secret",
+					Namespace:       namespace,
+					OwnerReferences: []metav1.OwnerReference{ownerRef},
+					Annotations: map[string]string{
+						VersionAnnotation:  fmt.Sprint(itemVersion),
+						ItemPathAnnotation: itemPath,
+					},
+				},
+			}
+			cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+				WithRuntimeObjects(defaultNamespace, existingSecret).Build()
+
+			mockOpClient := &mocks.TestClient{}
+			mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(tc.item, tc.itemErr)
+			mockOpClient.On("GetItemsByTitle", mock.Anything, mock.Anything).Return([]model.Item{{ID: itemId}}, nil)
+			mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+
+			recorder := record.NewFakeRecorder(10)
+			h := &SecretUpdateHandler{
+				client:    cl,
+				apiReader: cl,
+				opClient:  mockOpClient,
+				recorder:  recorder,
+			}
+			assert.NoError(t, h.UpdateKubernetesSecretsTask(ctx))
+
+			// Every event is recorded on the secret and on its owner.
+			var expectedEvents []string
+			for _, event := range tc.expectedEvents {
+				expectedEvents = append(expectedEvents, event, event)
+			}
+			assert.Equal(t, expectedEvents, drainEvents(recorder))
+		})
+	}
+}
+
+func TestRestartWorkloadRecordsEvent(t *testing.T) {
+	ctx := context.Background()
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: namespace}}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(deployment).Build()
+
+	recorder := record.NewFakeRecorder(10)
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, recorder: recorder}
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: namespace}}
+	assert.NoError(t, h.restartWorkload(ctx, deployment, secret))
+	assert.Equal(t, []string{
+		fmt.Sprintf("Normal %s Restarted to pick up changes to Secret \"secret\"", ReasonWorkloadRestarted),
+	}, drainEvents(recorder))
+}
+
+// drainEvents returns the events recorded so far by a fake recorder.
+func drainEvents(recorder *record.FakeRecorder) []string {
+	var events []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
 func TestIsUpdatedSecret(t *testing.T) {
 	secretName := "test-secret"
 	updatedSecrets := map[string]*corev1.Secret{
@@ -1240,3 +1878,53 @@ func createItem() *model.Item {
 		CreatedAt: time.Now(),
 	}
 }
+
+func TestRestartWorkloadsWithUpdatedSecretsSkipsUnlistableWorkloadTypes(t *testing.T) {
+	ctx := context.Background()
+	secretName := "updated-secret"
+	containers := generateContainersWithSecretRefsFromEnv([]string{secretName})
+
+	rolloutGVK := schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).
+		WithObjects(
+			defaultNamespace,
+			&appsv1.StatefulSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "statefulset", Namespace: namespace},
+				Spec: appsv1.StatefulSetSpec{
+					Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: containers}},
+				},
+			},
+		).
+		WithInterceptorFuncs(interceptor.Funcs{
+			List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+				if _, ok := list.(*unstructured.UnstructuredList); ok {
+					return errors2.NewForbidden(schema.GroupResource{Group: rolloutGVK.Group, Resource: "rollouts"}, "",
+						fmt.Errorf("no RBAC"))
+				}
+				return c.List(ctx, list, opts...)
+			},
+		}).
+		Build()
+
+	h := &SecretUpdateHandler{
+		client:    cl,
+		apiReader: cl,
+		config: SecretUpdateHandlerConfig{
+			ShouldAutoRestartWorkloadsGlobally: true,
+			ExtraWorkloadTypes: []WorkloadType{
+				{GroupVersionKind: rolloutGVK, PodTemplatePath: []string{"spec", "template"}},
+			},
+		},
+	}
+
+	updatedSecrets := map[string]map[string]*corev1.Secret{
+		namespace: {secretName: {ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}}},
+	}
+	assert.NoError(t, h.restartWorkloadsWithUpdatedSecrets(ctx, updatedSecrets, nil))
+	h.processRestarts(ctx)
+
+	statefulSet := &appsv1.StatefulSet{}
+	assert.NoError(t, cl.Get(ctx, types.NamespacedName{Name: "statefulset", Namespace: namespace}, statefulSet))
+	assert.Contains(t, statefulSet.Spec.Template.Annotations, RestartAnnotation,
+		"Workload types that cannot be listed should not keep others from being restarted")
+}
@@ -0,0 +1,91 @@
+package onepassword
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kubectl/pkg/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/1Password/onepassword-operator/pkg/mocks"
+	opclient "github.com/1Password/onepassword-operator/pkg/onepassword/client"
+	"github.com/1Password/onepassword-operator/pkg/onepassword/model"
+	"github.com/1Password/onepassword-operator/pkg/tracing/tracingtest"
+)
+
+func TestGetOnePasswordItemByPathTracing(t *testing.T) {
+	exporter := tracingtest.Install(t)
+
+	item := &model.Item{ID: itemId, VaultID: vaultId, Files: []model.File{{ID: "file-id"}}}
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetVaultsByTitle", "Employee").Return([]model.Vault{{ID: vaultId}}, nil)
+	mockOpClient.On("GetItemsByTitle", vaultId, "Database").Return([]model.Item{{ID: itemId}}, nil)
+	mockOpClient.On("GetItemByID", vaultId, itemId).Return(item, nil)
+	mockOpClient.On("GetFileContent", vaultId, itemId, "file-id").Return([]byte("content"), nil)
+	opClient := opclient.Chain(mockOpClient, opclient.Tracing(opclient.BackendConnect))
+
+	_, err := GetOnePasswordItemByPath(context.Background(), opClient, "vaults/Employee/items/Database")
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"Client.GetVaultsByTitle",
+		"getVaultID",
+		"Client.GetItemsByTitle",
+		"getItemIDByTitle",
+		"Client.GetItemByID",
+		"Client.GetFileContent",
+		"LoadItemFiles",
+		"GetOnePasswordItemByPath",
+	}, tracingtest.SpanNames(exporter))
+
+	spans := exporter.GetSpans()
+	root := spans[len(spans)-1]
+	byName := map[string]int{}
+	for i, span := range spans {
+		byName[span.Name] = i
+		assert.Equal(t, root.SpanContext.TraceID(), span.SpanContext.TraceID(), span.Name)
+	}
+	assert.Equal(t, root.SpanContext.SpanID(), spans[byName["getVaultID"]].Parent.SpanID())
+	assert.Equal(t, root.SpanContext.SpanID(), spans[byName["Client.GetItemByID"]].Parent.SpanID())
+	assert.Equal(t, spans[byName["LoadItemFiles"]].SpanContext.SpanID(), spans[byName["Client.GetFileContent"]].Parent.SpanID())
+}
+
+func TestUpdateSecretHandlerTracing(t *testing.T) {
+	exporter := tracingtest.Install(t)
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "secret",
+			Namespace: namespace,
+			Annotations: map[string]string{
+				VersionAnnotation:  fmt.Sprint(itemVersion),
+				ItemPathAnnotation: itemPath,
+			},
+		},
+	}
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithRuntimeObjects(secret).Build()
+
+	mockOpClient := &mocks.TestClient{}
+	mockOpClient.On("GetVaultsByTitle", mock.Anything).Return([]model.Vault{}, nil)
+	mockOpClient.On("GetItemsByTitle", mock.Anything, mock.Anything).Return([]model.Item{}, nil)
+	mockOpClient.On("GetItemByID", mock.Anything, mock.Anything).Return(nil, opclient.ErrRateLimited)
+
+	h := &SecretUpdateHandler{client: cl, apiReader: cl, opClient: mockOpClient}
+	require.NoError(t, h.UpdateKubernetesSecretsTask(context.Background()))
+
+	spans := exporter.GetSpans()
+	require.Equal(t, []string{
+		"getVaultID",
+		"SecretUpdateHandler.updateKubernetesSecret",
+		"SecretUpdateHandler.UpdateKubernetesSecretsTask",
+	}, tracingtest.SpanNames(exporter))
+	assert.Equal(t, codes.Error, spans[1].Status.Code, "Transient errors should fail the span of the secret")
+	assert.Equal(t, spans[2].SpanContext.SpanID(), spans[1].Parent.SpanID())
+}
@@ -18,20 +18,44 @@ func AppendUpdatedVolumeSecrets(
 	secrets map[string]*corev1.Secret,
 	updatedDeploymentSecrets map[string]*corev1.Secret,
 ) map[string]*corev1.Secret {
-	for i := 0; i < len(volumes); i++ {
-		secret := IsVolumeUsingSecret(volumes[i], secrets)
-		if secret != nil {
-			updatedDeploymentSecrets[secret.Name] = secret
-		} else {
-			secretProjection := IsVolumeUsingSecretProjection(volumes[i], secrets)
-			if secretProjection != nil {
-				updatedDeploymentSecrets[secretProjection.Name] = secretProjection
+	for _, volume := range volumes {
+		volumeSecretRefs(volume, func(name string, _ []string) {
+			secret, ok := secrets[name]
+			if ok {
+				updatedDeploymentSecrets[secret.Name] = secret
 			}
-		}
+		})
 	}
 	return updatedDeploymentSecrets
 }
 
+// volumeSecretRefs calls ref with the name of each secret the volume mounts, directly or projected,
+// and the keys it mounts, nil when it lists no items and mounts all keys.
+func volumeSecretRefs(volume corev1.Volume, ref func(name string, keys []string)) {
+	if volume.Secret != nil {
+		ref(volume.Secret.SecretName, volumeItemKeys(volume.Secret.Items))
+	}
+	if volume.Projected != nil {
+		for _, source := range volume.Projected.Sources {
+			if source.Secret != nil {
+				ref(source.Secret.Name, volumeItemKeys(source.Secret.Items))
+			}
+		}
+	}
+}
+
+// volumeItemKeys returns the keys mounted by a secret volume, nil when it lists no items and mounts all keys.
+func volumeItemKeys(items []corev1.KeyToPath) []string {
+	if len(items) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(items))
+	for _, item := range items {
+		keys = append(keys, item.Key)
+	}
+	return keys
+}
+
 func IsVolumeUsingSecret(volume corev1.Volume, secrets map[string]*corev1.Secret) *corev1.Secret {
 	if secret := volume.Secret; secret != nil {
 		secretName := secret.SecretName
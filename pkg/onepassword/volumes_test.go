@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestAreVolmesUsingSecrets(t *testing.T) {
@@ -51,3 +52,22 @@ func TestAreVolumesNotUsingSecrets(t *testing.T) {
 		t.Errorf("Expected that volumes were not using secrets but they were detected.")
 	}
 }
+
+func TestAppendUpdatedVolumeSecretsParsesProjectedSources(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-app-token":        {ObjectMeta: metav1.ObjectMeta{Name: "onepassword-app-token"}},
+		"onepassword-user-credentials": {ObjectMeta: metav1.ObjectMeta{Name: "onepassword-user-credentials"}},
+	}
+
+	volumes := []corev1.Volume{generateVolumesProjected([]string{
+		"onepassword-app-token",
+		"some_other_key",
+		"onepassword-user-credentials",
+	})}
+
+	updatedDeploymentSecrets := AppendUpdatedVolumeSecrets(volumes, secretNamesToSearch, map[string]*corev1.Secret{})
+
+	if len(updatedDeploymentSecrets) != len(secretNamesToSearch) {
+		t.Errorf("Expected that all updated Secrets of the projected volume are found, got %v", len(updatedDeploymentSecrets))
+	}
+}
@@ -0,0 +1,129 @@
+package onepassword
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// NewWorkloads returns an empty object of every workload kind that can use the annotation-driven flow.
+func NewWorkloads() []client.Object {
+	return []client.Object{
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
+		&appsv1.DaemonSet{},
+		&batchv1.Job{},
+		&batchv1.CronJob{},
+	}
+}
+
+// NewWorkloadLists returns an empty list of every workload kind returned by NewWorkloads.
+func NewWorkloadLists() []client.ObjectList {
+	return []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&appsv1.DaemonSetList{},
+		&batchv1.JobList{},
+		&batchv1.CronJobList{},
+	}
+}
+
+// GetWorkloadPodTemplate returns the pod template of a supported workload.
+func GetWorkloadPodTemplate(workload client.Object) (*corev1.PodTemplateSpec, error) {
+	switch w := workload.(type) {
+	case *appsv1.Deployment:
+		return &w.Spec.Template, nil
+	case *appsv1.StatefulSet:
+		return &w.Spec.Template, nil
+	case *appsv1.DaemonSet:
+		return &w.Spec.Template, nil
+	case *batchv1.Job:
+		return &w.Spec.Template, nil
+	case *batchv1.CronJob:
+		return &w.Spec.JobTemplate.Spec.Template, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %T", workload)
+	}
+}
+
+// GetAnnotationsForWorkload returns the 1Password annotations set on the workload,
+// falling back to the annotations of its pod template.
+func GetAnnotationsForWorkload(workload client.Object, regex *regexp.Regexp) (map[string]string, bool) {
+	annotations := FilterAnnotations(workload.GetAnnotations(), regex)
+	if len(annotations) > 0 {
+		return annotations, true
+	}
+
+	podTemplate, err := GetWorkloadPodTemplate(workload)
+	if err != nil {
+		return annotations, false
+	}
+	annotations = FilterAnnotations(podTemplate.Annotations, regex)
+	return annotations, len(annotations) > 0
+}
+
+// IsWorkloadUsingSecrets reports whether the workload's annotations, containers or volumes reference any of the secrets.
+func IsWorkloadUsingSecrets(workload client.Object, secrets map[string]*corev1.Secret) bool {
+	podTemplate, err := GetWorkloadPodTemplate(workload)
+	if err != nil {
+		return false
+	}
+
+	volumes := podTemplate.Spec.Volumes
+	containers := podTemplate.Spec.Containers
+	containers = append(containers, podTemplate.Spec.InitContainers...)
+	return AreAnnotationsUsingSecrets(workload.GetAnnotations(), secrets) ||
+		AreAnnotationsUsingSecrets(podTemplate.Annotations, secrets) ||
+		AreContainersUsingSecrets(containers, secrets) ||
+		AreVolumesUsingSecrets(volumes, secrets)
+}
+
+// WorkloadType is a custom resource kind with an embedded pod template, such as an Argo Rollout
+// or a Knative Service. It is handled as an unstructured object so no Go types are needed for it.
+type WorkloadType struct {
+	GroupVersionKind schema.GroupVersionKind
+	// PodTemplatePath is the field path to the pod template, e.g. ["spec", "template"].
+	PodTemplatePath []string
+}
+
+// ParseWorkloadTypes parses a comma separated list of group/version/Kind=path entries, e.g.
+// "argoproj.io/v1alpha1/Rollout=spec.template,serving.knative.dev/v1/Service=spec.template".
+func ParseWorkloadTypes(value string) ([]WorkloadType, error) {
+	var workloadTypes []WorkloadType
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, path, found := strings.Cut(entry, "=")
+		if !found || path == "" {
+			return nil, fmt.Errorf("workload type %q must be in the format group/version/Kind=path", entry)
+		}
+		parts := strings.Split(kind, "/")
+		var gvk schema.GroupVersionKind
+		switch len(parts) {
+		case 2:
+			gvk = schema.GroupVersionKind{Version: parts[0], Kind: parts[1]}
+		case 3:
+			gvk = schema.GroupVersionKind{Group: parts[0], Version: parts[1], Kind: parts[2]}
+		default:
+			return nil, fmt.Errorf("workload type %q must be in the format group/version/Kind=path", entry)
+		}
+		if gvk.Version == "" || gvk.Kind == "" {
+			return nil, fmt.Errorf("workload type %q must be in the format group/version/Kind=path", entry)
+		}
+
+		workloadTypes = append(workloadTypes, WorkloadType{
+			GroupVersionKind: gvk,
+			PodTemplatePath:  strings.Split(path, "."),
+		})
+	}
+	return workloadTypes, nil
+}
@@ -0,0 +1,170 @@
+package onepassword
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestIsDeploymentUsingSecretsUsingVolumes(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-database-secret":  {},
+		"onepassword-api-key":          {},
+		"onepassword-app-token":        {},
+		"onepassword-user-credentials": {},
+	}
+
+	volumeSecretNames := []string{
+		"onepassword-database-secret",
+		"onepassword-api-key",
+	}
+
+	volumes := generateVolumes(volumeSecretNames)
+
+	volumeProjectedSecretNames := []string{
+		"onepassword-app-token",
+		"onepassword-user-credentials",
+	}
+
+	volumeProjected := generateVolumesProjected(volumeProjectedSecretNames)
+
+	volumes = append(volumes, volumeProjected)
+
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Volumes = volumes
+	if !IsWorkloadUsingSecrets(deployment, secretNamesToSearch) {
+		t.Errorf("Expected that deployment was using secrets but they were not detected.")
+	}
+}
+
+func TestIsDeploymentUsingSecretsUsingContainers(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-database-secret": {},
+		"onepassword-api-key":         {},
+	}
+
+	containerSecretNames := []string{
+		"onepassword-database-secret",
+		"onepassword-api-key",
+		"some_other_key",
+	}
+
+	deployment := &appsv1.Deployment{}
+	deployment.Spec.Template.Spec.Containers = generateContainersWithSecretRefsFromEnv(containerSecretNames)
+	if !IsWorkloadUsingSecrets(deployment, secretNamesToSearch) {
+		t.Errorf("Expected that deployment was using secrets but they were not detected.")
+	}
+}
+
+func TestIsDeploymentNotUSingSecrets(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-database-secret": {},
+		"onepassword-api-key":         {},
+	}
+
+	deployment := &appsv1.Deployment{}
+	if IsWorkloadUsingSecrets(deployment, secretNamesToSearch) {
+		t.Errorf("Expected that deployment was using not secrets but they were detected.")
+	}
+}
+
+func TestIsWorkloadUsingSecretsForWorkloadKinds(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-database-secret": {},
+	}
+	containers := generateContainersWithSecretRefsFromEnv([]string{"onepassword-database-secret"})
+
+	statefulSet := &appsv1.StatefulSet{}
+	statefulSet.Spec.Template.Spec.Containers = containers
+	daemonSet := &appsv1.DaemonSet{}
+	daemonSet.Spec.Template.Spec.InitContainers = containers
+	job := &batchv1.Job{}
+	job.Spec.Template.Spec.Containers = containers
+	cronJob := &batchv1.CronJob{}
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers = containers
+
+	for _, workload := range []client.Object{statefulSet, daemonSet, job, cronJob} {
+		if !IsWorkloadUsingSecrets(workload, secretNamesToSearch) {
+			t.Errorf("Expected that %T was using secrets but they were not detected.", workload)
+		}
+	}
+}
+
+func TestIsWorkloadUsingSecretsFromTemplateAnnotations(t *testing.T) {
+	secretNamesToSearch := map[string]*corev1.Secret{
+		"onepassword-database-secret": {},
+	}
+
+	cronJob := &batchv1.CronJob{}
+	cronJob.Spec.JobTemplate.Spec.Template.Annotations = map[string]string{
+		NameAnnotation: "onepassword-database-secret",
+	}
+	if !IsWorkloadUsingSecrets(cronJob, secretNamesToSearch) {
+		t.Errorf("Expected that cronjob was using secrets but they were not detected.")
+	}
+}
+
+func TestGetWorkloadPodTemplateUnsupportedType(t *testing.T) {
+	if _, err := GetWorkloadPodTemplate(&corev1.Pod{}); err == nil {
+		t.Errorf("Expected an error for an unsupported workload type")
+	}
+}
+
+func TestParseWorkloadTypes(t *testing.T) {
+	testCases := map[string]struct {
+		value         string
+		expected      []WorkloadType
+		expectedError bool
+	}{
+		"empty": {
+			value: "",
+		},
+		"multiple entries": {
+			value: "argoproj.io/v1alpha1/Rollout=spec.template, serving.knative.dev/v1/Service=spec.template",
+			expected: []WorkloadType{
+				{
+					GroupVersionKind: schema.GroupVersionKind{Group: "argoproj.io", Version: "v1alpha1", Kind: "Rollout"},
+					PodTemplatePath:  []string{"spec", "template"},
+				},
+				{
+					GroupVersionKind: schema.GroupVersionKind{Group: "serving.knative.dev", Version: "v1", Kind: "Service"},
+					PodTemplatePath:  []string{"spec", "template"},
+				},
+			},
+		},
+		"core group": {
+			value: "v1/PodTemplate=template",
+			expected: []WorkloadType{
+				{
+					GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "PodTemplate"},
+					PodTemplatePath:  []string{"template"},
+				},
+			},
+		},
+		"missing path": {
+			value:         "argoproj.io/v1alpha1/Rollout",
+			expectedError: true,
+		},
+		"missing kind": {
+			value:         "Rollout=spec.template",
+			expectedError: true,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			workloadTypes, err := ParseWorkloadTypes(tc.value)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, workloadTypes)
+		})
+	}
+}
@@ -0,0 +1,100 @@
+// Package tracing traces syncs from 1Password with OpenTelemetry, from reconciling a resource to
+// resolving and fetching its item and writing the Kubernetes secret.
+//
+// Spans are started with the global tracer provider, which does nothing until Setup installs one.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// ServiceName identifies the operator in the exported traces.
+	ServiceName = "onepassword-operator"
+
+	tracerName = "github.com/1Password/onepassword-operator"
+)
+
+// Config configures the export of traces.
+type Config struct {
+	// SamplingRatio is the fraction of traces started by the operator that are exported, between 0 and 1.
+	// Spans whose parent was sampled are always exported.
+	SamplingRatio float64
+	// ServiceVersion is the version of the operator reported with the traces.
+	ServiceVersion string
+}
+
+// Enabled reports whether an OTLP endpoint is set with the standard OTEL_EXPORTER_OTLP_ENDPOINT
+// or OTEL_EXPORTER_OTLP_TRACES_ENDPOINT environment variables.
+func Enabled() bool {
+	for _, env := range []string{"OTEL_EXPORTER_OTLP_ENDPOINT", "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"} {
+		if value, found := os.LookupEnv(env); found && value != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Setup installs a global tracer provider exporting spans over OTLP gRPC. The exporter is configured with
+// the standard OTEL_EXPORTER_OTLP_* environment variables. The returned function flushes the remaining
+// spans and stops the export.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	exporter, err := otlptracegrpc.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+	provider, err := NewTracerProvider(ctx, cfg, sdktrace.WithBatcher(exporter))
+	if err != nil {
+		return nil, err
+	}
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	return provider.Shutdown, nil
+}
+
+// NewTracerProvider creates a tracer provider sampling traces as configured by cfg. Spans are exported
+// with the span processors in opts, e.g. sdktrace.WithSyncer(tracetest.NewInMemoryExporter()) in tests.
+func NewTracerProvider(ctx context.Context, cfg Config, opts ...sdktrace.TracerProviderOption) (*sdktrace.TracerProvider, error) {
+	if cfg.SamplingRatio < 0 || cfg.SamplingRatio > 1 {
+		return nil, fmt.Errorf("invalid sampling ratio %v, must be between 0 and 1", cfg.SamplingRatio)
+	}
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(cfg.ServiceVersion)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SamplingRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...), nil
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it as failed when err is not nil.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
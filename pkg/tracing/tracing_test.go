@@ -0,0 +1,89 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewTracerProviderSampling(t *testing.T) {
+	testCases := map[string]struct {
+		ratio         float64
+		sampledParent bool
+		expectedSpans int
+	}{
+		"all traces": {
+			ratio:         1,
+			expectedSpans: 1,
+		},
+		"no traces": {
+			ratio:         0,
+			expectedSpans: 0,
+		},
+		"sampled parent": {
+			ratio:         0,
+			sampledParent: true,
+			expectedSpans: 1,
+		},
+	}
+
+	for description, tc := range testCases {
+		t.Run(description, func(t *testing.T) {
+			exporter := tracetest.NewInMemoryExporter()
+			provider, err := NewTracerProvider(context.Background(), Config{SamplingRatio: tc.ratio, ServiceVersion: "1.2.3"},
+				sdktrace.WithSyncer(exporter))
+			require.NoError(t, err)
+
+			ctx := context.Background()
+			if tc.sampledParent {
+				ctx = trace.ContextWithRemoteSpanContext(ctx, trace.NewSpanContext(trace.SpanContextConfig{
+					TraceID:    trace.TraceID{1},
+					SpanID:     trace.SpanID{1},
+					TraceFlags: trace.FlagsSampled,
+				}))
+			}
+			_, span := provider.Tracer("test").Start(ctx, "span")
+			span.End()
+
+			spans := exporter.GetSpans()
+			require.Len(t, spans, tc.expectedSpans)
+			if len(spans) > 0 {
+				attrs := spans[0].Resource.Attributes()
+				require.Contains(t, attrs, semconv.ServiceName(ServiceName))
+				require.Contains(t, attrs, semconv.ServiceVersion("1.2.3"))
+			}
+		})
+	}
+}
+
+func TestNewTracerProviderInvalidRatio(t *testing.T) {
+	_, err := NewTracerProvider(context.Background(), Config{SamplingRatio: 2})
+	require.Error(t, err)
+}
+
+func TestEnd(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := NewTracerProvider(context.Background(), Config{SamplingRatio: 1}, sdktrace.WithSyncer(exporter))
+	require.NoError(t, err)
+	tracer := provider.Tracer("test")
+
+	_, span := tracer.Start(context.Background(), "succeeded", trace.WithAttributes(attribute.String("key", "value")))
+	End(span, nil)
+	_, span = tracer.Start(context.Background(), "failed")
+	End(span, errors.New("item not found"))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 2)
+	require.Equal(t, codes.Unset, spans[0].Status.Code)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+	require.Equal(t, "item not found", spans[1].Status.Description)
+	require.Len(t, spans[1].Events, 1, "The error should be recorded as an event")
+}
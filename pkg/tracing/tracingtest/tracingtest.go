@@ -0,0 +1,41 @@
+// Package tracingtest records the spans started during a test in memory.
+package tracingtest
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/1Password/onepassword-operator/pkg/tracing"
+)
+
+// Install makes the global tracer provider record every span in the returned exporter until the test ends.
+func Install(t testing.TB) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider, err := tracing.NewTracerProvider(context.Background(), tracing.Config{SamplingRatio: 1},
+		sdktrace.WithSyncer(exporter))
+	if err != nil {
+		t.Fatalf("failed to create tracer provider: %v", err)
+	}
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		_ = provider.Shutdown(context.Background())
+	})
+	return exporter
+}
+
+// SpanNames returns the names of the recorded spans in the order they ended.
+func SpanNames(exporter *tracetest.InMemoryExporter) []string {
+	var names []string
+	for _, span := range exporter.GetSpans() {
+		names = append(names, span.Name)
+	}
+	return names
+}